@@ -0,0 +1,655 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"jianggujin.com/lvs/cmd/module"
+	"jianggujin.com/lvs/internal/cache"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/util"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	util.AddCommand(rootCmd, &BundleCommand{})
+}
+
+const (
+	bundleFormat       = 1
+	bundleManifestName = "manifest.json"
+)
+
+// bundleManifest 离线包清单，作为离线包中的第一个文件
+type bundleManifest struct {
+	Format     int                          `json:"format"`
+	LvsVersion string                       `json:"lvsVersion"`
+	Created    time.Time                    `json:"created"`
+	Os         string                       `json:"os"`
+	Arch       string                       `json:"arch"`
+	Toolchains []*bundleToolchain           `json:"toolchains"`
+	Aliases    map[string]map[string]string `json:"aliases"`
+	Files      map[string]string            `json:"files"` // 离线包中文件的sha256摘要
+}
+
+type bundleToolchain struct {
+	Module  string `json:"module"`
+	Version string `json:"version"`
+	Archive string `json:"archive,omitempty"` // 缓存的归档文件，为空时离线包中为安装目录
+}
+
+// bundleMember 离线包中的文件
+type bundleMember struct {
+	name string
+	path string
+	info os.FileInfo
+	link string
+}
+
+type BundleCommand struct {
+	Versions map[string]*[]string
+	Output   string
+	Force    bool
+}
+
+func (command *BundleCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Export or import installed toolchains for offline machines",
+	}
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Package installed versions, checksums and aliases into a bundle file",
+		RunE:  command.exportRunE,
+	}
+	flags := exportCmd.Flags()
+	command.Versions = make(map[string]*[]string)
+	for name := range config.Modules {
+		versions := new([]string)
+		flags.StringSliceVar(versions, name, nil, fmt.Sprintf("installed %s versions to export", name))
+		command.Versions[name] = versions
+	}
+	flags.StringVarP(&command.Output, "output", "o", "toolchains.lvsb", "bundle file path")
+	cmd.AddCommand(exportCmd)
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Restore toolchains from a bundle file",
+		Args:  cobra.ExactArgs(1),
+		RunE:  command.importRunE,
+	}
+	importCmd.Flags().BoolVarP(&command.Force, "force", "f", false, "overwrite existing aliases with the same name")
+	cmd.AddCommand(importCmd)
+	return cmd
+}
+
+func (command *BundleCommand) exportRunE(*cobra.Command, []string) error {
+	manifest := &bundleManifest{
+		Format:     bundleFormat,
+		LvsVersion: config.BuildVersion,
+		Created:    time.Now(),
+		Os:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Aliases:    make(map[string]map[string]string),
+		Files:      make(map[string]string),
+	}
+	var names []string
+	for name := range command.Versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var members []*bundleMember
+	for _, name := range names {
+		versions := *command.Versions[name]
+		if len(versions) == 0 {
+			continue
+		}
+		c := module.Lookup(name)
+		if c == nil {
+			return util.WrapErrorMsg("module [%s] is not supported on this platform", name)
+		}
+		home := config.GetPath(c.Keys().Home)
+		exported := make(map[string]bool)
+		for _, v := range versions {
+			version, err := c.ResolveInstalled(v)
+			if err != nil {
+				return util.WrapError(err)
+			}
+			dir := filepath.Join(home, version)
+			if !util.Exists(c.Executable(dir)) {
+				return util.WrapErrorMsg("[%s %s] is not installed", name, version)
+			}
+			toolchain := &bundleToolchain{Module: name, Version: version}
+			items, err := command.collect(c, toolchain, dir)
+			if err != nil {
+				return util.WrapErrorMsg("collect [%s %s] files error", name, version).SetErr(err)
+			}
+			manifest.Toolchains = append(manifest.Toolchains, toolchain)
+			members = append(members, items...)
+			exported[version] = true
+		}
+		// 仅导出指向已导出版本的别名
+		for alias, version := range c.Aliases() {
+			if exported[version] {
+				if manifest.Aliases[name] == nil {
+					manifest.Aliases[name] = make(map[string]string)
+				}
+				manifest.Aliases[name][alias] = version
+			}
+		}
+	}
+	if len(manifest.Toolchains) == 0 {
+		return util.WrapErrorMsg("no version specified, such as --go 1.22.3")
+	}
+
+	// 清单位于离线包开头，需要预先计算摘要
+	for _, member := range members {
+		if !member.info.Mode().IsRegular() {
+			continue
+		}
+		checksum, err := util.FileChecksum(member.path)
+		if err != nil {
+			return util.WrapErrorMsg("calculate [%s] checksum error", member.path).SetErr(err)
+		}
+		manifest.Files[member.name] = checksum
+	}
+
+	if err := command.write(manifest, members); err != nil {
+		_ = os.Remove(command.Output)
+		return util.WrapErrorMsg("export bundle [%s] error", command.Output).SetErr(err)
+	}
+	for _, toolchain := range manifest.Toolchains {
+		fmt.Printf("export [%s %s] finish\n", toolchain.Module, toolchain.Version)
+	}
+	return nil
+}
+
+// collect 优先使用缓存的归档文件，不存在时打包安装目录
+func (command *BundleCommand) collect(c *module.Command, toolchain *bundleToolchain, dir string) ([]*bundleMember, error) {
+	download, err := c.ConvertDownload(toolchain.Version)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := cache.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name, download.BaseName+".") {
+			continue
+		}
+		info, err := os.Stat(entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		toolchain.Archive = path.Join("archives", toolchain.Module, entry.Name)
+		return []*bundleMember{{name: toolchain.Archive, path: entry.Path, info: info}}, nil
+	}
+
+	var members []*bundleMember
+	prefix := path.Join("installed", toolchain.Module, toolchain.Version)
+	err = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		member := &bundleMember{name: path.Join(prefix, filepath.ToSlash(rel)), path: p, info: info}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if member.link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+		members = append(members, member)
+		return nil
+	})
+	return members, err
+}
+
+func (command *BundleCommand) write(manifest *bundleManifest, members []*bundleMember) error {
+	file, err := os.Create(command.Output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	bar := util.DefaultBytes(-1, fmt.Sprintf("export bundle [%s]", command.Output))
+	defer bar.Close()
+	gw := gzip.NewWriter(io.MultiWriter(file, bar))
+	tw := tar.NewWriter(gw)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     bundleManifestName,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  manifest.Created,
+	}); err != nil {
+		return err
+	}
+	if _, err = tw.Write(data); err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		header, err := tar.FileInfoHeader(member.info, member.link)
+		if err != nil {
+			return err
+		}
+		header.Name = member.name
+		if member.info.IsDir() {
+			header.Name += "/"
+		}
+		if err = tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !member.info.Mode().IsRegular() {
+			continue
+		}
+		if err = command.copyMember(tw, member, manifest.Files[member.name]); err != nil {
+			return err
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func (command *BundleCommand) copyMember(w io.Writer, member *bundleMember, checksum string) error {
+	file, err := os.Open(member.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	h := util.NewChecksum()
+	if _, err = io.Copy(io.MultiWriter(w, h), file); err != nil {
+		return err
+	}
+	if hex.EncodeToString(h.Sum(nil)) != checksum {
+		return fmt.Errorf("file [%s] was modified during export", member.path)
+	}
+	return nil
+}
+
+func (command *BundleCommand) importRunE(_ *cobra.Command, args []string) (err error) {
+	file, err := os.Open(args[0])
+	if err != nil {
+		return util.WrapErrorMsg("open bundle [%s] error", args[0]).SetErr(err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return util.WrapError(err)
+	}
+	bar := util.DefaultBytes(info.Size(), fmt.Sprintf("import bundle [%s]", args[0]))
+	defer bar.Close()
+	gr, err := gzip.NewReader(io.TeeReader(file, bar))
+	if err != nil {
+		return util.WrapErrorMsg("[%s] is not a valid bundle", args[0]).SetErr(err)
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+
+	manifest, err := command.readManifest(tr)
+	if err != nil {
+		return util.WrapErrorMsg("[%s] is not a valid bundle", args[0]).SetErr(err)
+	}
+	importer, err := newBundleImporter(manifest, command.Force)
+	if err != nil {
+		return util.WrapErrorMsg("import bundle [%s] error", args[0]).SetErr(err)
+	}
+	defer func() {
+		if err != nil {
+			importer.rollback()
+		}
+	}()
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return util.WrapErrorMsg("read bundle [%s] error", args[0]).SetErr(err)
+		}
+		if err = importer.add(header, tr); err != nil {
+			return util.WrapErrorMsg("import [%s] error", header.Name).SetErr(err)
+		}
+	}
+	_ = bar.Finish()
+	if err = importer.finish(); err != nil {
+		return util.WrapErrorMsg("import bundle [%s] error", args[0]).SetErr(err)
+	}
+	return nil
+}
+
+func (command *BundleCommand) readManifest(tr *tar.Reader) (*bundleManifest, error) {
+	header, err := tr.Next()
+	if err != nil {
+		return nil, err
+	}
+	if header.Name != bundleManifestName {
+		return nil, errors.New("manifest not found")
+	}
+	manifest := &bundleManifest{}
+	if err = json.NewDecoder(tr).Decode(manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Format != bundleFormat {
+		return nil, fmt.Errorf("unsupported bundle format %d", manifest.Format)
+	}
+	if manifest.Os != runtime.GOOS || manifest.Arch != runtime.GOARCH {
+		return nil, fmt.Errorf("bundle was exported on %s/%s and cannot be used on %s/%s", manifest.Os, manifest.Arch, runtime.GOOS, runtime.GOARCH)
+	}
+	return manifest, nil
+}
+
+// bundleImporter 将离线包中的文件还原到各模块的安装目录
+type bundleImporter struct {
+	manifest *bundleManifest
+	modules  map[string]*module.Command
+	archives map[string]*bundleToolchain // 归档文件路径与版本
+	dirs     map[string]*bundleToolchain // 安装目录路径与版本
+	skipped  map[*bundleToolchain]bool   // 本地已完整安装的版本
+	created  map[*bundleToolchain]bool   // 本次导入创建安装目录的版本，导入失败时仅删除这些目录
+	repaired map[*bundleToolchain]string // 安装不完整的版本，原安装目录移动到的备份目录，导入失败时恢复
+	cached   map[*bundleToolchain]*cache.Entry
+	force    bool // 覆盖已存在的同名别名
+}
+
+func newBundleImporter(manifest *bundleManifest, force bool) (*bundleImporter, error) {
+	importer := &bundleImporter{
+		manifest: manifest,
+		force:    force,
+		modules:  make(map[string]*module.Command),
+		archives: make(map[string]*bundleToolchain),
+		dirs:     make(map[string]*bundleToolchain),
+		skipped:  make(map[*bundleToolchain]bool),
+		created:  make(map[*bundleToolchain]bool),
+		repaired: make(map[*bundleToolchain]string),
+		cached:   make(map[*bundleToolchain]*cache.Entry),
+	}
+	for _, toolchain := range manifest.Toolchains {
+		c := module.Lookup(toolchain.Module)
+		if c == nil {
+			return nil, fmt.Errorf("module [%s] is not supported on this platform", toolchain.Module)
+		}
+		importer.modules[toolchain.Module] = c
+		if err := checkBundleVersion(c, toolchain.Version); err != nil {
+			return nil, err
+		}
+		if toolchain.Archive != "" {
+			download, err := c.ParseArchive(path.Base(toolchain.Archive))
+			if err != nil {
+				return nil, err
+			}
+			if download.Version != toolchain.Version {
+				return nil, fmt.Errorf("archive [%s] does not match version [%s]", toolchain.Archive, toolchain.Version)
+			}
+			importer.archives[toolchain.Archive] = toolchain
+		} else {
+			importer.dirs[path.Join("installed", toolchain.Module, toolchain.Version)] = toolchain
+		}
+	}
+	for _, toolchain := range manifest.Toolchains {
+		if err := importer.prepare(toolchain); err != nil {
+			importer.rollback()
+			return nil, err
+		}
+	}
+	return importer, nil
+}
+
+// prepare 根据安装信息判断版本是否已完整安装，安装信息在解压完成后写入，
+// 不存在时视为安装中断，将原安装目录移动到备份目录后重新导入
+func (importer *bundleImporter) prepare(toolchain *bundleToolchain) error {
+	dir := importer.dir(toolchain)
+	if util.Exists(importer.modules[toolchain.Module].Executable(dir)) && module.ReadMetadata(dir) != nil {
+		importer.skipped[toolchain] = true
+		return nil
+	}
+	if _, err := os.Lstat(dir); err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		importer.created[toolchain] = true
+		return nil
+	}
+	backup := filepath.Join(filepath.Dir(dir), fmt.Sprintf(".%s.lvs-repair", toolchain.Version))
+	if err := os.RemoveAll(backup); err != nil {
+		return err
+	}
+	if err := os.Rename(dir, backup); err != nil {
+		return err
+	}
+	fmt.Printf("[%s %s] is incomplete, reinstall from the bundle\n", toolchain.Module, toolchain.Version)
+	importer.created[toolchain] = true
+	importer.repaired[toolchain] = backup
+	return nil
+}
+
+// checkBundleVersion 版本号会作为安装目录的名称，必须是合法的版本号且不能包含路径分隔符
+func checkBundleVersion(c *module.Command, version string) error {
+	if version == "" || strings.ContainsAny(version, `/\`) || strings.Contains(version, "..") {
+		return fmt.Errorf("invalid version [%s]", version)
+	}
+	if _, err := c.Semver(version); err != nil {
+		return fmt.Errorf("invalid version [%s]: %w", version, err)
+	}
+	return nil
+}
+
+func (importer *bundleImporter) dir(toolchain *bundleToolchain) string {
+	return filepath.Join(config.GetPath(importer.modules[toolchain.Module].Keys().Home), toolchain.Version)
+}
+
+func (importer *bundleImporter) add(header *tar.Header, r io.Reader) error {
+	name := path.Clean(header.Name)
+	if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return errors.New("unsafe file path")
+	}
+	if toolchain, ok := importer.archives[name]; ok {
+		if importer.skipped[toolchain] {
+			return nil
+		}
+		return importer.addArchive(toolchain, name, r)
+	}
+	parts := strings.SplitN(name, "/", 4)
+	if len(parts) < 3 {
+		return errors.New("unexpected file")
+	}
+	toolchain, ok := importer.dirs[path.Join(parts[0], parts[1], parts[2])]
+	if !ok {
+		return errors.New("unexpected file")
+	}
+	if importer.skipped[toolchain] {
+		return nil
+	}
+	root := importer.dir(toolchain)
+	if len(parts) < 4 {
+		if header.Typeflag != tar.TypeDir {
+			return errors.New("unexpected file")
+		}
+		return os.MkdirAll(root, os.FileMode(header.Mode)|0700)
+	}
+	rel := parts[3]
+	// 父目录为符号链接时写入的文件会位于安装目录之外
+	if err := checkBundleParents(root, rel); err != nil {
+		return err
+	}
+	target := filepath.Join(root, filepath.FromSlash(rel))
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(target, os.FileMode(header.Mode)|0700)
+	case tar.TypeSymlink:
+		if err := checkBundleLink(rel, header.Linkname); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return err
+		}
+		_ = os.Remove(target)
+		return util.Symlink(target, header.Linkname)
+	case tar.TypeReg:
+		if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return errors.New("refuse to write through a symbolic link")
+		}
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return err
+		}
+		return importer.writeFile(target, os.FileMode(header.Mode), importer.manifest.Files[name], r)
+	}
+	return fmt.Errorf("unsupported file type %c", header.Typeflag)
+}
+
+// checkBundleParents 检查安装目录中rel的各级父目录均不是符号链接
+func checkBundleParents(root, rel string) error {
+	dir := root
+	parts := strings.Split(rel, "/")
+	for _, part := range parts[:len(parts)-1] {
+		dir = filepath.Join(dir, part)
+		info, err := os.Lstat(dir)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("parent directory [%s] is a symbolic link", dir)
+		}
+	}
+	return nil
+}
+
+// checkBundleLink 符号链接只能指向安装目录中的文件，如 bin/npm -> ../lib/node_modules/npm/bin/npm-cli.js
+func checkBundleLink(rel, link string) error {
+	if link == "" || path.IsAbs(link) || filepath.IsAbs(link) || filepath.VolumeName(link) != "" || strings.Contains(link, `\`) {
+		return fmt.Errorf("unsafe link target [%s]", link)
+	}
+	target := path.Join(path.Dir(rel), link)
+	if target == ".." || strings.HasPrefix(target, "../") {
+		return fmt.Errorf("link target [%s] is outside of the installation directory", link)
+	}
+	return nil
+}
+
+// addArchive 校验后的归档文件存入缓存，离线包读取完成后再解压
+func (importer *bundleImporter) addArchive(toolchain *bundleToolchain, name string, r io.Reader) error {
+	tempHome := config.GetPath(config.KeyLvsTempHome)
+	if err := os.MkdirAll(tempHome, os.ModePerm); err != nil {
+		return err
+	}
+	tempPath := filepath.Join(tempHome, fmt.Sprintf("%s-%s", time.Now().Format("20060102150405"), path.Base(name)))
+	defer os.Remove(tempPath)
+	checksum := importer.manifest.Files[name]
+	if err := importer.writeFile(tempPath, 0644, checksum, r); err != nil {
+		return err
+	}
+	entry, err := cache.Store(checksum, path.Base(name), tempPath)
+	if err != nil {
+		return err
+	}
+	importer.cached[toolchain] = entry
+	return nil
+}
+
+func (importer *bundleImporter) writeFile(target string, mode os.FileMode, checksum string, r io.Reader) error {
+	if checksum == "" {
+		return errors.New("checksum not found in manifest")
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	h := util.NewChecksum()
+	if _, err = io.Copy(io.MultiWriter(file, h), r); err != nil {
+		return err
+	}
+	return util.VerifyChecksum(filepath.Base(target), checksum, h)
+}
+
+func (importer *bundleImporter) finish() error {
+	for _, toolchain := range importer.manifest.Toolchains {
+		if importer.skipped[toolchain] {
+			fmt.Printf("[%s %s] is already installed, skip\n", toolchain.Module, toolchain.Version)
+			continue
+		}
+		c := importer.modules[toolchain.Module]
+		if entry, ok := importer.cached[toolchain]; ok {
+			download, err := c.ParseArchive(entry.Name)
+			if err != nil {
+				return err
+			}
+			bar := util.DefaultBytes(-1, fmt.Sprintf("extract [%s %s] archive files", toolchain.Module, toolchain.Version))
+			err = c.ExtractArchive(config.GetPath(c.Keys().Home), entry.Path, download, bar)
+			_ = bar.Close()
+			if err != nil {
+				return err
+			}
+		}
+		if !util.Exists(c.Executable(importer.dir(toolchain))) {
+			return fmt.Errorf("[%s %s] is incomplete in the bundle", toolchain.Module, toolchain.Version)
+		}
+		// 安装信息表示导入完成，离线包中的安装目录可能来自未记录安装信息的版本
+		_ = c.WriteMetadata(toolchain.Version)
+		fmt.Printf("import [%s %s] finish\n", toolchain.Module, toolchain.Version)
+	}
+	for _, backup := range importer.repaired {
+		_ = os.RemoveAll(backup)
+	}
+
+	var count int
+	for name, aliases := range importer.manifest.Aliases {
+		c := module.Lookup(name)
+		if c == nil {
+			continue
+		}
+		for alias, version := range aliases {
+			key := c.Keys().Alias + alias
+			existing := config.GetString(key)
+			if existing == version {
+				continue
+			}
+			if existing != "" && !importer.force {
+				fmt.Printf("alias [%s %s] already points to [%s], skip, use --force to overwrite it with [%s]\n", name, alias, existing, version)
+				continue
+			}
+			config.Set(key, version)
+			count++
+		}
+	}
+	if count > 0 {
+		return config.SaveConfig()
+	}
+	return nil
+}
+
+// rollback 删除本次导入创建的安装目录并恢复安装不完整的原目录，导入前已存在的目录不受影响
+func (importer *bundleImporter) rollback() {
+	for _, toolchain := range importer.manifest.Toolchains {
+		if importer.created[toolchain] {
+			_ = os.RemoveAll(importer.dir(toolchain))
+		}
+		if backup, ok := importer.repaired[toolchain]; ok {
+			_ = os.Rename(backup, importer.dir(toolchain))
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/internal/cache"
+	"jianggujin.com/lvs/internal/util"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	util.AddCommand(rootCmd, &CacheCommand{})
+}
+
+type CacheCommand struct {
+	OlderThan string
+}
+
+func (command *CacheCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the downloaded archive cache",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Short:   "List cached archives",
+		Aliases: []string{"ls"},
+		RunE:    command.listRunE,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "size",
+		Short: "Display the total size of cached archives",
+		RunE:  command.sizeRunE,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "clean",
+		Short: "Remove all cached archives",
+		RunE:  command.cleanRunE,
+	})
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove cached archives that have not been used for a period of time",
+		RunE:  command.pruneRunE,
+	}
+	pruneCmd.Flags().StringVar(&command.OlderThan, "older-than", "720h", "remove archives not used within this duration, such as 720h or 30d")
+	cmd.AddCommand(pruneCmd)
+	return cmd
+}
+
+func (command *CacheCommand) listRunE(*cobra.Command, []string) error {
+	entries, err := cache.List()
+	if err != nil {
+		return util.WrapErrorMsg("list cached archives error").SetErr(err)
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Size", "Last Used", "Sha256"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetCenterSeparator("|")
+	for _, entry := range entries {
+		table.Append([]string{entry.Name, util.FormatBytes(entry.Size), entry.ModTime.Format(time.DateTime), entry.Digest[:12]})
+	}
+	table.Render()
+	return nil
+}
+
+func (command *CacheCommand) sizeRunE(*cobra.Command, []string) error {
+	entries, err := cache.List()
+	if err != nil {
+		return util.WrapErrorMsg("list cached archives error").SetErr(err)
+	}
+	var size int64
+	for _, entry := range entries {
+		size += entry.Size
+	}
+	fmt.Printf("%s (%d archives) in %s\n", util.FormatBytes(size), len(entries), cache.Home())
+	return nil
+}
+
+func (command *CacheCommand) cleanRunE(*cobra.Command, []string) error {
+	return command.remove(func(*cache.Entry) bool {
+		return true
+	})
+}
+
+func (command *CacheCommand) pruneRunE(*cobra.Command, []string) error {
+	age, err := parseAge(command.OlderThan)
+	if err != nil {
+		return util.WrapErrorMsg("[%s] is not a valid duration", command.OlderThan).SetErr(err)
+	}
+	deadline := time.Now().Add(-age)
+	return command.remove(func(entry *cache.Entry) bool {
+		return entry.ModTime.Before(deadline)
+	})
+}
+
+func (command *CacheCommand) remove(filter func(*cache.Entry) bool) error {
+	entries, err := cache.List()
+	if err != nil {
+		return util.WrapErrorMsg("list cached archives error").SetErr(err)
+	}
+	var count int
+	var size int64
+	for _, entry := range entries {
+		if !filter(entry) {
+			continue
+		}
+		if err = cache.Remove(entry); err != nil {
+			return util.WrapErrorMsg("remove cached archive [%s] error", entry.Name).SetErr(err)
+		}
+		count++
+		size += entry.Size
+	}
+	fmt.Printf("removed %d archives, freed %s\n", count, util.FormatBytes(size))
+	return nil
+}
+
+// parseAge 解析时长，在time.ParseDuration的基础上支持以d表示天数
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, errors.New("invalid number of days")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
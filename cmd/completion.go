@@ -0,0 +1,102 @@
+//go:build !windows
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/install"
+	"jianggujin.com/lvs/internal/util"
+	"os"
+	"path/filepath"
+)
+
+// completionsDir zsh补全脚本目录，位于DATA_HOME中，需要在compinit之前添加到fpath
+const completionsDir = "completions"
+
+type CompletionCommand struct {
+	Install bool
+}
+
+func init() {
+	util.AddCommand(rootCmd, &CompletionCommand{})
+}
+
+func (command *CompletionCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion [bash|zsh|fish]",
+		Short: "Print or install the shell completion script",
+		Long: `Print or install the shell completion script, defaults to SHELL_TYPE.
+Versions, aliases and configuration keys are completed dynamically, remote versions are completed from the cached indexes only.
+Add one of the following lines to the shell config file, or run 'lvs completion --install':
+  bash: eval "$(lvs completion bash)"
+  zsh:  lvs completion zsh > "${fpath[1]}/_lvs"
+  fish: lvs completion fish | source`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		RunE:      command.RunE,
+	}
+	flags := cmd.Flags()
+	flags.BoolVarP(&command.Install, "install", "i", false, "write the completion into SHELL_CONFIG_PATH, only the shell of SHELL_TYPE is allowed")
+	return cmd
+}
+
+func (command *CompletionCommand) RunE(_ *cobra.Command, args []string) error {
+	shellType := config.GetString(config.KeyShellType)
+	if len(args) > 0 {
+		if command.Install && args[0] != shellType {
+			return util.WrapErrorMsg("the shell [%s] is not the SHELL_TYPE [%s], the completion can not be installed", args[0], shellType)
+		}
+		shellType = args[0]
+	}
+	var buf bytes.Buffer
+	if err := genCompletion(&buf, shellType); err != nil {
+		return err
+	}
+	if !command.Install {
+		fmt.Print(buf.String())
+		return nil
+	}
+	line, err := completionLine(shellType, buf.Bytes())
+	if err != nil {
+		return util.WrapErrorMsg("install completion error").SetErr(err)
+	}
+	if err = install.InstallLines(line); err != nil {
+		return util.WrapErrorMsg("install completion error").SetErr(err)
+	}
+	fmt.Printf("completion installed, please restart the terminal or run 'source %s'\n", config.GetString(config.KeyShellConfigPath))
+	return nil
+}
+
+func genCompletion(buf *bytes.Buffer, shellType string) error {
+	switch shellType {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(buf, true)
+	case "zsh":
+		return rootCmd.GenZshCompletion(buf)
+	case "fish":
+		return rootCmd.GenFishCompletion(buf, true)
+	}
+	return util.WrapErrorMsg("the shell [%s] is not supported, only bash, zsh or fish is allowed", shellType)
+}
+
+// completionLine 加载补全脚本的语句，zsh的补全脚本需要写入fpath中的目录，由compinit加载
+func completionLine(shellType string, script []byte) (string, error) {
+	lvs := lvsExecutable()
+	switch shellType {
+	case "bash":
+		return fmt.Sprintf(`eval "$(%s completion bash)"`, lvs), nil
+	case "fish":
+		return fmt.Sprintf("%s completion fish | source", lvs), nil
+	}
+	dir := filepath.Join(config.GetPath(config.KeyLvsDataHome), completionsDir)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "_"+rootCmd.Name()), script, 0644); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("fpath=(%q $fpath)", dir), nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/mitchellh/go-homedir"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/cmd/module"
 	"jianggujin.com/lvs/internal/config"
 	"jianggujin.com/lvs/internal/install"
 	"jianggujin.com/lvs/internal/util"
@@ -14,6 +15,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 )
 
 func init() {
@@ -31,21 +33,42 @@ type ConfigCommand struct {
 
 func (command *ConfigCommand) Init() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:    "config",
-		Short:  "Set or read LVS configuration",
-		Long:   "Set or read LVS configuration. When only the configuration key is specified, it is read configuration; otherwise, it is set configuration",
-		PreRun: command.preRun,
-		RunE:   command.RunE,
+		Use:               "config",
+		Short:             "Set or read LVS configuration",
+		Long:              "Set or read LVS configuration. When only the configuration key is specified, it is read configuration; otherwise, it is set configuration",
+		PreRun:            command.preRun,
+		RunE:              command.RunE,
+		ValidArgsFunction: command.complete,
 	}
 	return cmd
 }
 
+// complete 补全配置名称
+func (command *ConfigCommand) complete(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	command.preRun(nil, nil)
+	var configKeys []string
+	for k := range command.configKeys {
+		configKeys = append(configKeys, k)
+	}
+	sort.Strings(configKeys)
+	return module.Completions(configKeys, args, strings.ToUpper(toComplete)), cobra.ShellCompDirectiveNoFileComp
+}
+
 func (command *ConfigCommand) preRun(_ *cobra.Command, _ []string) {
 	command.configKeys = map[string]*ConfigValidator{
-		config.KeyLvsDataHome:       {Setter: command.setEnvDirConfig},
-		config.KeyLvsTempHome:       {Setter: command.setDirConfig},
-		config.KeyLvsProxy:          {Setter: command.setProxyConfig},
-		config.KeyLvsDefaultCommand: {Setter: command.setConfig},
+		config.KeyLvsDataHome:          {Setter: command.setEnvDirConfig},
+		config.KeyLvsTempHome:          {Setter: command.setDirConfig},
+		config.KeyLvsTempExpire:        {Setter: command.setDurationConfig},
+		config.KeyLvsCacheHome:         {Setter: command.setDirConfig},
+		config.KeyLvsIndexTtl:          {Setter: command.setDurationConfig},
+		config.KeyLvsProxy:             {Setter: command.setProxyConfig},
+		config.KeyLvsDefaultCommand:    {Setter: command.setConfig},
+		config.KeyLvsMirrorStrategy:    {Setter: command.setMirrorStrategyConfig},
+		config.KeyLvsWorkspaceBoundary: {Setter: command.setWorkspaceBoundaryConfig},
+		config.KeyLvsVersionMode:       {Setter: command.setVersionModeConfig},
 
 		config.KeyGoHome:    {Setter: command.setDirConfig},
 		config.KeyGoSymlink: {Setter: command.setSymlinkConfig},
@@ -57,6 +80,7 @@ func (command *ConfigCommand) preRun(_ *cobra.Command, _ []string) {
 		command.configKeys[config.KeyNodeSymlink] = &ConfigValidator{Setter: command.setSymlinkConfig}
 		command.configKeys[config.KeyNodeProxy] = &ConfigValidator{Setter: command.setProxyConfig}
 		command.configKeys[config.KeyNodeMirror] = &ConfigValidator{Setter: command.setMirrorConfig}
+		command.configKeys[config.KeyNodeKeyring] = &ConfigValidator{Setter: command.setFileConfig}
 	}
 	command.initConfigKeys()
 }
@@ -199,21 +223,70 @@ func (command *ConfigCommand) setProxyConfig(name, value string) error {
 	return command.setConfig(name, value)
 }
 
+// setMirrorConfig 支持逗号分隔的多个镜像地址，请求失败时按照MIRROR_STRATEGY依次尝试
 func (command *ConfigCommand) setMirrorConfig(name, value string) error {
 	if value != "none" && value != "" {
-		if !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
-			return fmt.Errorf("the mirror address [%s] protocol is illegal, only http or https is allowed", value)
-		}
-		if !strings.HasSuffix(value, "/") {
-			value = value + "/"
-		}
-		if _, err := url.Parse(value); err != nil {
-			return err
+		var mirrors []string
+		for _, mirror := range strings.Split(value, ",") {
+			mirror = strings.TrimSpace(mirror)
+			if mirror == "" {
+				continue
+			}
+			if !strings.HasPrefix(mirror, "http://") && !strings.HasPrefix(mirror, "https://") {
+				return fmt.Errorf("the mirror address [%s] protocol is illegal, only http or https is allowed", mirror)
+			}
+			if !strings.HasSuffix(mirror, "/") {
+				mirror = mirror + "/"
+			}
+			if _, err := url.Parse(mirror); err != nil {
+				return err
+			}
+			mirrors = append(mirrors, mirror)
 		}
+		value = strings.Join(mirrors, ",")
+	}
+	return command.setConfig(name, value)
+}
+
+func (command *ConfigCommand) setMirrorStrategyConfig(name, value string) error {
+	if value != "none" && value != "" && value != config.MirrorStrategyOrder && value != config.MirrorStrategyLatency {
+		return fmt.Errorf("the mirror strategy [%s] is illegal, only %s or %s is allowed", value, config.MirrorStrategyOrder, config.MirrorStrategyLatency)
 	}
 	return command.setConfig(name, value)
 }
 
+func (command *ConfigCommand) setVersionModeConfig(name, value string) error {
+	if value != "none" && value != "" && value != config.VersionModeSymlink && value != config.VersionModeShims {
+		return fmt.Errorf("the version mode [%s] is illegal, only %s or %s is allowed", value, config.VersionModeSymlink, config.VersionModeShims)
+	}
+	return command.setConfig(name, value)
+}
+
+// setWorkspaceBoundaryConfig 边界可以为vcs、home、root或目录，多个值使用逗号分隔
+func (command *ConfigCommand) setWorkspaceBoundaryConfig(name, value string) error {
+	if value == "none" {
+		return command.setConfig(name, value)
+	}
+	var boundaries []string
+	for _, boundary := range strings.Split(value, ",") {
+		boundary = strings.TrimSpace(boundary)
+		switch boundary {
+		case "":
+			continue
+		case config.BoundaryVcs, config.BoundaryHome, config.BoundaryRoot:
+		default:
+			path, err := homedir.Expand(boundary)
+			if err != nil || !filepath.IsAbs(path) {
+				return fmt.Errorf("the workspace boundary [%s] is illegal, only %s, %s, %s or an absolute directory is allowed",
+					boundary, config.BoundaryVcs, config.BoundaryHome, config.BoundaryRoot)
+			}
+			boundary = filepath.Clean(path)
+		}
+		boundaries = append(boundaries, boundary)
+	}
+	return command.setConfig(name, strings.Join(boundaries, ","))
+}
+
 func (command *ConfigCommand) setEnvDirConfig(name, value string) error {
 	return command.checkDirConfig(value, func(s string) error {
 		return install.Install(map[string]string{
@@ -268,6 +341,15 @@ func (command *ConfigCommand) setFileConfig(name, value string) error {
 	return command.setConfig(name, value)
 }
 
+func (command *ConfigCommand) setDurationConfig(name, value string) error {
+	if value != "none" && value != "" {
+		if _, err := time.ParseDuration(value); err != nil {
+			return err
+		}
+	}
+	return command.setConfig(name, value)
+}
+
 func (command *ConfigCommand) setBooleanConfig(name, value string) error {
 	value = strings.ToLower(value)
 	if "true" == value || "1" == value || "y" == value {
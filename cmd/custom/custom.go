@@ -9,6 +9,7 @@ import (
 	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/transform"
 	"io"
+	"jianggujin.com/lvs/cmd/module"
 	"jianggujin.com/lvs/internal/config"
 	"jianggujin.com/lvs/internal/install"
 	"jianggujin.com/lvs/internal/invoke"
@@ -17,6 +18,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 )
@@ -51,14 +53,14 @@ func Init(rootCmd *cobra.Command) {
 	}
 	defer reader.Close()
 	data, err := io.ReadAll(reader)
-	var customs []*Custom
-	if err = json.Unmarshal(data, &customs); err != nil {
+	var list []*Custom
+	if err = json.Unmarshal(data, &list); err != nil {
 		return
 	}
 
-	injects := []func(*cobra.Command, *Custom){injectCurrent, injectInstall, injectList, injectUninstall, injectUse}
+	injects := []func(*cobra.Command, *Custom){injectCurrent, injectInstall, injectList, injectUninstall, injectUse, injectGlobal, injectAlias}
 	// 注册自定义命令
-	for _, custom := range customs {
+	for _, custom := range list {
 		name := strings.TrimSpace(custom.Name)
 		if name == "" {
 			continue
@@ -76,6 +78,8 @@ func Init(rootCmd *cobra.Command) {
 		if _, ok := config.Modules[name]; ok {
 			continue
 		}
+		custom.Name = name
+		customs = append(customs, custom)
 		command := &cobra.Command{
 			Use:   name,
 			Short: name + " version management",
@@ -277,7 +281,23 @@ func injectUse(rootCmd *cobra.Command, custom *Custom) {
 		Use:     "use",
 		Short:   fmt.Sprintf("Activate the specified version of %s", custom.Name),
 		Aliases: []string{"u"},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return module.Completions(custom.Versions(), args, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
 		RunE: func(cmd *cobra.Command, versions []string) error {
+			if len(versions) == 0 {
+				// 未指定版本时使用工作空间中声明的版本
+				version, _, err := module.FindWorkspaceVersion(module.DefaultWorkspaceFiles(custom.Name))
+				if err != nil {
+					return util.WrapError(err)
+				}
+				if version != "" {
+					versions = []string{version}
+				}
+			}
 			if len(versions) != 1 {
 				fmt.Printf("Usage: %s %s use x.x.x\n", config.Name(), custom.Name)
 				return nil
@@ -328,3 +348,104 @@ func injectUse(rootCmd *cobra.Command, custom *Custom) {
 	}
 	rootCmd.AddCommand(cmd)
 }
+
+func injectGlobal(rootCmd *cobra.Command, custom *Custom) {
+	if custom.Home == "" {
+		return
+	}
+	cmd := &cobra.Command{
+		Use:   "global",
+		Short: "Set or display the default version used by the launchers when the workspace does not declare one",
+		Args:  cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return module.Completions(custom.Versions(), args, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key := custom.globalKey()
+			if len(args) == 0 {
+				version := config.GetString(key)
+				if version == "" {
+					fmt.Printf("no global version is set, you can run '%s %s global x.x.x' to set a version\n", config.Name(), custom.Name)
+					return nil
+				}
+				fmt.Printf("global: %s\n", version)
+				return nil
+			}
+			version := args[0]
+			// none 表示取消全局默认版本
+			if version == "none" {
+				version = ""
+			} else if _, err := custom.InstalledDir(version); err != nil {
+				return util.WrapError(err)
+			}
+			config.Set(key, version)
+			if err := config.SaveConfig(); err != nil {
+				return util.WrapErrorMsg("failed to save global version [%s]", version).SetErr(err)
+			}
+			if version == "" {
+				fmt.Println("global version has been unset")
+				return nil
+			}
+			fmt.Printf("global: %s\n", version)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(cmd)
+}
+
+func injectAlias(rootCmd *cobra.Command, custom *Custom) {
+	if custom.Home == "" {
+		return
+	}
+	cmd := &cobra.Command{
+		Use:     "alias",
+		Short:   "Set an alias for the specified version, none cancels the alias",
+		Aliases: []string{"tag"},
+		Args:    cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			prefix := custom.aliasKey()
+			if len(args) == 2 {
+				name, version := strings.ToLower(args[0]), args[1]
+				if version == "none" {
+					version = ""
+				}
+				config.Set(prefix+name, version)
+				if err := config.SaveConfig(); err != nil {
+					return util.WrapErrorMsg("failed to save alias [%s: %s]", name, version).SetErr(err)
+				}
+				fmt.Printf("%s: %s\n", name, version)
+				return nil
+			}
+			lowerPrefix := strings.ToLower(prefix)
+			aliases := config.Filter(func(s string) bool {
+				return strings.HasPrefix(s, lowerPrefix)
+			})
+			if len(args) == 1 {
+				name := strings.ToLower(args[0])
+				fmt.Printf("%s: %s\n", name, aliases[lowerPrefix+name])
+				return nil
+			}
+			var keys []string
+			for key := range aliases {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Alias", "Version"})
+			table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+			table.SetAlignment(tablewriter.ALIGN_CENTER)
+			table.SetCenterSeparator("|")
+			for _, key := range keys {
+				if aliases[key] != "" {
+					table.Append([]string{strings.TrimPrefix(key, lowerPrefix), aliases[key]})
+				}
+			}
+			table.Render()
+			return nil
+		},
+	}
+	rootCmd.AddCommand(cmd)
+}
@@ -0,0 +1,143 @@
+package custom
+
+import (
+	"fmt"
+	version2 "github.com/hashicorp/go-version"
+	"jianggujin.com/lvs/cmd/module"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/util"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// customs 已注册的自定义模块
+var customs []*Custom
+
+// Customs 所有已注册的自定义模块
+func Customs() []*Custom {
+	return customs
+}
+
+// Lookup 根据名称查找自定义模块，不存在时返回nil
+func Lookup(name string) *Custom {
+	for _, custom := range customs {
+		if custom.Name == name {
+			return custom
+		}
+	}
+	return nil
+}
+
+// BinDirs 将PathValues中的 %SymlinkEnvKey% 替换为版本目录，未配置时使用版本目录
+func (c *Custom) BinDirs(dir string) []string {
+	var dirs []string
+	placeholder := fmt.Sprintf("%%%s%%", c.SymlinkEnvKey)
+	for _, value := range c.PathValues {
+		if c.SymlinkEnvKey != "" && strings.Contains(value, placeholder) {
+			dirs = append(dirs, filepath.Clean(strings.ReplaceAll(value, placeholder, dir)))
+		}
+	}
+	if len(dirs) == 0 {
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// Versions Home中已安装的版本
+func (c *Custom) Versions() []string {
+	if c.Home == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(c.Home)
+	if err != nil {
+		return nil
+	}
+	var versions []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			versions = append(versions, entry.Name())
+		}
+	}
+	return versions
+}
+
+// Binaries 所有已安装版本中可执行文件的名称
+func (c *Custom) Binaries() []string {
+	var dirs []string
+	for _, version := range c.Versions() {
+		dirs = append(dirs, c.BinDirs(filepath.Join(c.Home, version))...)
+	}
+	return module.Executables(dirs...)
+}
+
+// aliasKey 版本别名配置的前缀，与内置模块一致，如 ALIAS_JAVA_
+func (c *Custom) aliasKey() string {
+	return "ALIAS_" + strings.ToUpper(c.Name) + "_"
+}
+
+// globalKey 全局默认版本的配置名称，与内置模块一致，如 GLOBAL_JAVA
+func (c *Custom) globalKey() string {
+	return "GLOBAL_" + strings.ToUpper(c.Name)
+}
+
+// ShimDir 启动器使用的版本目录，优先级依次为环境变量、工作空间版本、全局默认版本，均未声明时使用软链指向的版本
+func (c *Custom) ShimDir() (string, error) {
+	version := os.Getenv(module.VersionEnvKey(c.Name))
+	if version == "" {
+		var err error
+		if version, _, err = module.FindWorkspaceVersion(module.DefaultWorkspaceFiles(c.Name)); err != nil {
+			return "", err
+		}
+	}
+	if version == "" {
+		version = config.GetString(c.globalKey())
+	}
+	if version == "" {
+		if c.SymlinkPath == "" {
+			return "", fmt.Errorf("no version of [%s] is declared and the symlink is not configured", c.Name)
+		}
+		return config.GetPath(c.SymlinkPath), nil
+	}
+	return c.InstalledDir(version)
+}
+
+// InstalledDir 与内置模块一致，依次将别名、已安装的版本目录名称或版本范围转换为已安装版本的目录
+func (c *Custom) InstalledDir(version string) (string, error) {
+	if alias := config.GetString(c.aliasKey() + strings.ToLower(version)); alias != "" {
+		version = alias
+	}
+	// 版本来自工作空间中的文件，不能指向Home之外的目录
+	if version == "." || strings.Contains(version, "..") || strings.ContainsAny(version, `/\`) {
+		return "", fmt.Errorf("[%s] is not a valid version", version)
+	}
+	if c.Home == "" {
+		return "", fmt.Errorf("[%s] not found, the home of [%s] is not configured", version, c.Name)
+	}
+	if dir := filepath.Join(c.Home, version); util.Exists(dir) {
+		return dir, nil
+	}
+	r, err := module.ParseRange(version)
+	if err != nil {
+		return "", fmt.Errorf("[%s] not found", version)
+	}
+	// 版本目录名称可以是任意格式，只匹配可以解析为版本号的目录
+	names := make(map[*version2.Version]string)
+	var installed []*version2.Version
+	for _, name := range c.Versions() {
+		if semver, err := version2.NewVersion(strings.TrimPrefix(name, "v")); err == nil {
+			names[semver] = name
+			installed = append(installed, semver)
+		}
+	}
+	matched := r.Select(installed, true)
+	if matched == nil {
+		return "", fmt.Errorf("unable to find a version that matches the criteria [%s]", version)
+	}
+	return filepath.Join(c.Home, names[matched]), nil
+}
+
+// Reshim 为所有已安装版本中的可执行文件生成启动器，返回启动器的名称与冲突的可执行文件
+func (c *Custom) Reshim() ([]string, []*module.ShimConflict, error) {
+	return module.WriteShims(c.Name, c.Binaries())
+}
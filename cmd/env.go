@@ -0,0 +1,155 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/cmd/module"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/shell"
+	"jianggujin.com/lvs/internal/util"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type EnvCommand struct {
+	Shell string
+	Hook  bool
+}
+
+func init() {
+	util.AddCommand(rootCmd, &EnvCommand{})
+}
+
+func (command *EnvCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env [module@version...]",
+		Short: "Print shell statements that activate versions for the current session only",
+		Long: `Print shell statements that activate versions for the current session only, e.g. eval "$(lvs env go@1.21 node@20)".
+The symlink and the shell config file are not modified. A module without a version uses the workspace or global version,
+and all modules are activated when no arguments are specified.`,
+		RunE: command.RunE,
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&command.Shell, "shell", "s", "", "shell type: zsh, bash, fish, csh, nu, pwsh or elvish, defaults to SHELL_TYPE")
+	flags.BoolVar(&command.Hook, "hook", false, "used by the shell hook, only print the changed statements of the workspace versions")
+	_ = flags.MarkHidden("hook")
+	return cmd
+}
+
+func (command *EnvCommand) RunE(_ *cobra.Command, args []string) error {
+	shellType := command.Shell
+	if shellType == "" {
+		shellType = config.GetString(config.KeyShellType)
+	}
+	if command.Hook {
+		args = nil
+		// 钩子在每次目录变化时执行，仅使用缓存的索引与已安装的版本解析别名，避免访问网络
+		config.Offline = true
+	}
+	envKeyValues, paths, err := sessionEnvs(args, command.Hook)
+	if err != nil {
+		return err
+	}
+	if command.Hook {
+		envKeyValues, paths = changedEnvs(envKeyValues, paths)
+	}
+	if len(envKeyValues) == 0 && len(paths) == 0 {
+		return nil
+	}
+	adapter := shell.NewShellAdapter(shellType, "")
+	fmt.Print(adapter.ExportEnvs(envKeyValues, paths))
+	return nil
+}
+
+// sessionEnvs 解析 module@version 并生成环境变量与PATH，未指定参数时使用所有模块在工作空间或全局声明的版本，
+// PATH中之前激活的版本目录会被移除，因此重复执行不会使PATH不断增长，quiet为true时无法解析的版本不输出提示
+func sessionEnvs(args []string, quiet bool) (map[string]string, []string, error) {
+	explicit := len(args) > 0
+	if !explicit {
+		for _, c := range module.Commands() {
+			args = append(args, c.Name())
+		}
+	}
+	envKeyValues := make(map[string]string)
+	paths := filepath.SplitList(os.Getenv("PATH"))
+	for _, arg := range args {
+		name, version, _ := strings.Cut(arg, "@")
+		c := module.Lookup(name)
+		m := config.Modules[name]
+		if c == nil || m == nil {
+			return nil, nil, util.WrapErrorMsg("module [%s] is not supported on this platform", name)
+		}
+		if version == "" {
+			var err error
+			if version, _, err = c.DefaultVersion(); err != nil {
+				if quiet {
+					continue
+				}
+				return nil, nil, util.WrapError(err)
+			}
+			if version == "" {
+				if explicit {
+					return nil, nil, util.WrapErrorMsg("no version of [%s] is declared in the workspace or global", name)
+				}
+				// 离开声明版本的工作空间后恢复为软链指向的版本
+				home := config.GetPath(c.Keys().Home)
+				if underDir(os.Getenv(m.SymlinkEnvKey), home) {
+					envKeyValues[m.SymlinkEnvKey] = m.EnvKeyValues[m.SymlinkEnvKey]
+					paths = withoutDir(paths, home)
+				}
+				continue
+			}
+		}
+		dir, err := c.InstalledDir(version)
+		if err != nil {
+			if !explicit {
+				// 未安装或无法解析工作空间声明的版本时保留当前环境
+				if !quiet {
+					fmt.Fprintf(os.Stderr, "lvs: %s %s\n", name, err)
+				}
+				continue
+			}
+			return nil, nil, util.WrapError(err)
+		}
+		envKeyValues[m.SymlinkEnvKey] = dir
+		paths = append([]string{c.BinDir(dir)}, withoutDir(paths, config.GetPath(c.Keys().Home))...)
+	}
+	return envKeyValues, paths, nil
+}
+
+// changedEnvs 仅保留与当前环境不同的环境变量，PATH未变化时返回nil
+func changedEnvs(envKeyValues map[string]string, paths []string) (map[string]string, []string) {
+	changed := make(map[string]string)
+	for key, value := range envKeyValues {
+		if os.Getenv(key) != value {
+			changed[key] = value
+		}
+	}
+	if strings.Join(paths, string(filepath.ListSeparator)) == os.Getenv("PATH") {
+		paths = nil
+	}
+	return changed, paths
+}
+
+// withoutDir 移除位于home目录中的PATH目录
+func withoutDir(paths []string, home string) []string {
+	var result []string
+	for _, path := range paths {
+		if !underDir(path, home) {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// underDir 判断path是否位于dir目录中
+func underDir(path, dir string) bool {
+	if path == "" {
+		return false
+	}
+	prefix := filepath.Clean(dir) + string(filepath.Separator)
+	return strings.HasPrefix(filepath.Clean(path)+string(filepath.Separator), prefix)
+}
@@ -4,40 +4,46 @@ import (
 	"fmt"
 	version2 "github.com/hashicorp/go-version"
 	"github.com/spf13/cobra"
-	"io"
+	"github.com/spf13/pflag"
+	"jianggujin.com/lvs/cmd/module"
 	"jianggujin.com/lvs/internal/config"
 	"jianggujin.com/lvs/internal/invoke"
-	"jianggujin.com/lvs/internal/util"
-	"net/http"
+	"path/filepath"
 	"regexp"
 	"runtime"
-	"sort"
 	"strings"
-	"time"
 )
 
-type Command struct {
-	command *cobra.Command
+type Provider struct {
+	Prerelease bool
+	versions   module.Collection
+	releases   []*Release
 }
 
-var goCmd = &Command{
-	command: &cobra.Command{
-		Use:   "go",
-		Short: "Go version management",
-	},
+func Init(rootCmd *cobra.Command) {
+	module.Init(rootCmd, &Provider{})
 }
 
-func Init(rootCmd *cobra.Command) {
-	rootCmd.AddCommand(goCmd.command)
+func (p *Provider) Name() string {
+	return config.ModuleGo
 }
 
-func (c *Command) AddCommand(commands ...util.Command) {
-	for _, cmd := range commands {
-		util.AddCommand(c.command, cmd)
+func (p *Provider) Title() string {
+	return "Go"
+}
+
+func (p *Provider) Keys() *module.Keys {
+	return &module.Keys{
+		Home:    config.KeyGoHome,
+		Symlink: config.KeyGoSymlink,
+		Mirror:  config.KeyGoMirror,
+		Proxy:   config.KeyGoProxy,
+		Alias:   config.KeyGoAliasPrefix,
+		Global:  config.KeyGoGlobal,
 	}
 }
 
-func (c *Command) Version() string {
+func (p *Provider) Current() string {
 	// go version go1.22.0 linux/amd64
 	str, err := invoke.GetInvoker().Command("go", "version")
 	if err != nil {
@@ -51,31 +57,21 @@ func (c *Command) Version() string {
 	return match[0]
 }
 
-func (c *Command) NewHttpClient(opts ...util.HttpClientOption) *http.Client {
-	ops := append([]util.HttpClientOption{util.WithProxyStr(config.GetStringWithDefault(config.KeyGoProxy, config.GetString(config.KeyLvsProxy)))}, opts...)
-	return util.NewHttpClient(ops...)
-}
-
-func (c *Command) Get(url string, opts ...util.HttpClientOption) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("User-Agent", fmt.Sprintf("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36 LVS/%s", config.BuildVersion))
-	return c.NewHttpClient(opts...).Do(req)
+// Version 远程版本，包含所有平台与类型的文件，Size与Sha256为当前平台归档文件的信息
+type Version struct {
+	Release
+	Size   string `json:"size"`
+	Sha256 string `json:"sha256"`
+	semver *version2.Version
 }
 
-type Version struct {
-	Version string `json:"version"` // Go 版本号，例如 "go1.22.0"
-	Size    string `json:"size"`
-	Sha256  string `json:"sha256"`
-	semver  *version2.Version
+func (v *Version) Raw() string {
+	return v.Version
 }
 
 func (v *Version) Semver() (*version2.Version, error) {
 	if v.semver == nil {
-		version, _ := strings.CutPrefix(v.Version, "go")
-		semver, err := version2.NewVersion(version)
+		semver, err := parseSemver(v.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -84,133 +80,147 @@ func (v *Version) Semver() (*version2.Version, error) {
 	return v.semver, nil
 }
 
-func (c *Command) Semver(version string) (*version2.Version, error) {
+func parseSemver(version string) (*version2.Version, error) {
 	version, _ = strings.CutPrefix(version, "go")
 	return version2.NewVersion(version)
 }
 
-func (c *Command) FixVersion(version string) string {
+func (p *Provider) Semver(version string) (*version2.Version, error) {
+	return parseSemver(version)
+}
+
+func (p *Provider) FixVersion(version string) string {
 	if len(version) > 2 && version[:2] != "go" {
 		version = "go" + version
 	}
 	return version
 }
 
-func (c *Command) RawVersion(version *version2.Version) string {
+func (p *Provider) RawVersion(version *version2.Version) string {
 	return "go" + version.Original()
 }
 
-type Collection []*Version
-
-func (v Collection) Len() int {
-	return len(v)
-}
-
-func (v Collection) Less(i, j int) bool {
-	return v[i].semver.GreaterThan(v[j].semver)
-}
-
-func (v Collection) Swap(i, j int) {
-	v[i], v[j] = v[j], v[i]
-}
-
-func (v Collection) Filter(filter func(*Version) (bool, error)) (Collection, error) {
-	if filter == nil {
-		return v, nil
-	}
-	result := make([]*Version, 0, len(v))
-	for _, version := range v {
-		ok, err := filter(version)
-		if err != nil {
-			return nil, err
+// fetchReleases 获取所有版本及其全部平台的文件
+func (p *Provider) fetchReleases() ([]*Release, error) {
+	if p.releases != nil {
+		return p.releases, nil
+	}
+	var releases []*Release
+	failover := module.NewFailover(config.KeyGoMirror, config.KeyGoProxy)
+	parse := func(content []byte, _ string) error {
+		var err error
+		releases, err = parseReleases(content)
+		return err
+	}
+	name := config.ModuleGo + "-releases"
+	err := failover.FetchIndex(name, "?mode=json&include=all", &releases, parse)
+	if err != nil && !config.Offline {
+		// 静态镜像不支持查询参数时会返回错误，重新获取下载页面并从HTML中提取
+		if pageErr := failover.FetchIndex(name, "", &releases, parse); pageErr != nil {
+			return nil, fmt.Errorf("%w, fallback to the download page: %v", err, pageErr)
 		}
-		if ok {
-			result = append(result, version)
-		}
-	}
-	return result, nil
-}
-
-func (v Collection) Find(filter func(*Version) (bool, error)) (*Version, error) {
-	if filter == nil {
-		return nil, nil
+		err = nil
 	}
-	for _, version := range v {
-		ok, err := filter(version)
-		if err != nil {
-			return nil, err
-		}
-		if ok {
-			return version, nil
-		}
+	if err != nil {
+		return nil, err
 	}
-	return nil, nil
+	p.releases = releases
+	return releases, nil
 }
 
-func (c *Command) ListVersions(filter func(*Version) (bool, error)) ([]*Version, error) {
-	// 不使用?mode=json是因为返回数据不全，改为提取HTML信息
-	fetchUrl := config.GetString(config.KeyGoMirror)
-	resp, err := c.Get(fetchUrl, util.WithTimeout(30*time.Second))
+func (p *Provider) RemoteVersions() (module.Collection, error) {
+	if p.versions != nil {
+		return p.versions, nil
+	}
+	releases, err := p.fetchReleases()
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-	content, err := io.ReadAll(resp.Body)
-	re := regexp.MustCompile(`<tr[^>]*>\s*<td[^>]*>\s*<a[^>]*>([^<]+)</a>\s*</td>\s*<td[^>]*>([^<]*)</td>\s*<td[^>]*>[^<]*</td>\s*<td[^>]*>[^<]*</td>\s*<td[^>]*>([^<]*)</td>\s*<td[^>]*>\s*<tt>([^<]*)</tt>\s*</td>\s*</tr>`)
-	matches := re.FindAllStringSubmatch(string(content), -1)
-	var versions []*Version
-	ext := ".tar.gz"
-	if "windows" == runtime.GOOS {
-		ext = ".zip"
-	}
-	suffix := fmt.Sprintf(".%s-%s%s", runtime.GOOS, runtime.GOARCH, ext)
-	for _, item := range matches {
-		name := strings.TrimSpace(item[1])
-		if !strings.HasSuffix(name, suffix) {
-			continue
-		}
-		kind := strings.TrimSpace(strings.ToLower(item[2]))
-		if kind != "archive" {
+	var versions module.Collection
+	for _, release := range releases {
+		archive := release.File(runtime.GOOS, runtime.GOARCH, KindArchive)
+		if archive == nil {
 			continue
 		}
-		version := &Version{
-			Version: name[0 : len(name)-len(suffix)],
-			Size:    strings.TrimSpace(item[3]),
-			Sha256:  strings.TrimSpace(item[4]),
-		}
-		if _, err = version.Semver(); err != nil {
-			continue
-		}
-		versions = append(versions, version)
+		versions = append(versions, &Version{
+			Release: *release,
+			Size:    archive.FormatSize(),
+			Sha256:  archive.Sha256,
+		})
 	}
+	p.versions = versions.Sort()
+	return p.versions, nil
+}
 
-	defaultFilter := func(nv *Version) (bool, error) {
-		if filter != nil {
-			return filter(nv)
-		}
-		return true, nil
-	}
-	sort.Sort(Collection(versions))
-	return Collection(versions).Filter(defaultFilter)
+func (p *Provider) Columns() []string {
+	return []string{"Size"}
 }
 
-type Download struct {
-	Version  string
-	BaseName string
-	Ext      string
+func (p *Provider) Row(version module.Version) []string {
+	return []string{version.(*Version).Size}
 }
 
-func (c *Command) ConvertDownload(version string) (*Download, error) {
+func (p *Provider) InstallFlags(flags *pflag.FlagSet) {
+	flags.BoolVarP(&p.Prerelease, "prerelease", "p", false, "prerelease version, if false, do not use")
+}
+
+func (p *Provider) InstallFilter(version module.Version) (bool, error) {
+	se, err := version.Semver()
+	if err != nil {
+		return false, err
+	}
+	return p.Prerelease || se.Prerelease() == "", nil
+}
+
+func (p *Provider) ConvertDownload(version string) (*module.Download, error) {
 	ext := "tar.gz"
 	if runtime.GOOS == "windows" {
 		ext = "zip"
 	}
-	return &Download{
+	return &module.Download{
 		Version:  version,
 		BaseName: fmt.Sprintf("%s.%s-%s", version, runtime.GOOS, runtime.GOARCH),
 		Ext:      ext,
 	}, nil
 }
+
+var archiveRegexp = regexp.MustCompile(`^(go\d+(?:\.\d+){0,2}(?:(?:rc|beta)\d+)?)\.`)
+
+func (p *Provider) ArchiveVersion(name string) string {
+	// go1.22.3.linux-amd64.tar.gz
+	match := archiveRegexp.FindStringSubmatch(name)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+func (p *Provider) DownloadPath(download *module.Download) string {
+	return fmt.Sprintf("%s.%s", download.BaseName, download.Ext)
+}
+
+func (p *Provider) Checksum(download *module.Download) (string, error) {
+	// 下载页面中已包含各版本归档文件的摘要
+	versions, err := p.RemoteVersions()
+	if err != nil {
+		return "", err
+	}
+	version, err := versions.Find(func(version module.Version) (bool, error) {
+		return version.Raw() == download.Version, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if version == nil || version.(*Version).Sha256 == "" {
+		return "", fmt.Errorf("checksum of [%s.%s] not found", download.BaseName, download.Ext)
+	}
+	return version.(*Version).Sha256, nil
+}
+
+func (p *Provider) ArchiveRoot(*module.Download) string {
+	return "go"
+}
+
+func (p *Provider) BinDir(dir string) string {
+	return filepath.Join(dir, "bin")
+}
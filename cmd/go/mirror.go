@@ -0,0 +1,131 @@
+package gom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"jianggujin.com/lvs/cmd/module"
+	"jianggujin.com/lvs/internal/util"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// go1.22.3.linux-amd64.tar.gz、go1.22.3.windows-amd64.zip
+var mirrorArchiveRegexp = regexp.MustCompile(`^(go\d+(?:\.\d+){0,2}(?:(?:rc|beta)\d+)?)\.([a-z0-9]+-[a-z0-9]+)\.(tar\.gz|zip)$`)
+
+func (p *Provider) ParseMirrorArchive(name string) *module.MirrorArchive {
+	match := mirrorArchiveRegexp.FindStringSubmatch(name)
+	if match == nil {
+		return nil
+	}
+	return &module.MirrorArchive{
+		Version:  match[1],
+		Platform: match[2],
+		Ext:      match[3],
+		Name:     name,
+	}
+}
+
+func (p *Provider) MirrorPath(archive *module.MirrorArchive) string {
+	return archive.Name
+}
+
+// MirrorIndexes 生成与官方下载页面结构一致的HTML，保证RemoteVersions可以正常解析
+func (p *Provider) MirrorIndexes(archives []*module.MirrorArchive) (map[string][]byte, error) {
+	sorted := make([]*module.MirrorArchive, len(archives))
+	copy(sorted, archives)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name > sorted[j].Name
+	})
+	var buf bytes.Buffer
+	buf.WriteString("<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"><title>Go Downloads</title></head>\n<body>\n")
+	buf.WriteString("<table class=\"downloadtable\">\n<thead>\n<tr><th>File name</th><th>Kind</th><th>OS</th><th>Arch</th><th>Size</th><th>SHA256 Checksum</th></tr>\n</thead>\n")
+	for _, archive := range sorted {
+		name := html.EscapeString(archive.Name)
+		goos, goarch, _ := strings.Cut(archive.Platform, "-")
+		_, _ = fmt.Fprintf(&buf, "<tr><td class=\"filename\"><a class=\"download\" href=\"%s\">%s</a></td><td>Archive</td><td>%s</td><td>%s</td><td>%s</td><td><tt>%s</tt></td></tr>\n",
+			name, name, html.EscapeString(goos), html.EscapeString(goarch), util.FormatBytes(archive.Size), archive.Sha256)
+	}
+	buf.WriteString("</table>\n</body>\n</html>\n")
+
+	data, err := json.Marshal(p.mirrorReleases(sorted))
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{"index.html": buf.Bytes(), "index.json": data}, nil
+}
+
+// mirrorReleases 将归档文件按版本分组，与官方JSON格式一致
+func (p *Provider) mirrorReleases(archives []*module.MirrorArchive) []*Release {
+	m := make(map[string]*Release)
+	var list module.Collection
+	for _, archive := range archives {
+		r, ok := m[archive.Version]
+		if !ok {
+			semver, err := parseSemver(archive.Version)
+			if err != nil {
+				continue
+			}
+			r = &Release{Version: archive.Version, Stable: semver.Prerelease() == ""}
+			m[archive.Version] = r
+			list = append(list, &Version{Release: *r})
+		}
+		goos, goarch, _ := strings.Cut(archive.Platform, "-")
+		r.Files = append(r.Files, &File{
+			Filename: archive.Name,
+			Os:       goos,
+			Arch:     goarch,
+			Version:  archive.Version,
+			Sha256:   archive.Sha256,
+			Size:     archive.Size,
+			Kind:     KindArchive,
+		})
+	}
+	releases := make([]*Release, 0, len(list))
+	for _, version := range list.Sort() {
+		releases = append(releases, m[version.Raw()])
+	}
+	return releases
+}
+
+func (p *Provider) MirrorIndex(rel string) bool {
+	return rel == "index.html" || rel == "index.json"
+}
+
+func (p *Provider) MirrorPlatform(goos, goarch string) string {
+	return goos + "-" + goarch
+}
+
+func (p *Provider) UpstreamArchives() ([]*module.MirrorArchive, error) {
+	releases, err := p.fetchReleases()
+	if err != nil {
+		return nil, err
+	}
+	var archives []*module.MirrorArchive
+	for _, release := range releases {
+		for _, file := range release.Files {
+			if file.Kind != KindArchive {
+				continue
+			}
+			archive := p.ParseMirrorArchive(file.Filename)
+			if archive == nil {
+				continue
+			}
+			archive.Sha256 = file.Sha256
+			archive.Size = file.Size
+			// Go不区分长期支持版本
+			archive.Lts = true
+			archives = append(archives, archive)
+		}
+	}
+	return archives, nil
+}
+
+func (p *Provider) UpstreamChecksum(archive *module.MirrorArchive) (string, error) {
+	if archive.Sha256 == "" {
+		return "", fmt.Errorf("checksum of [%s] not found", archive.Name)
+	}
+	return archive.Sha256, nil
+}
@@ -0,0 +1,118 @@
+package gom
+
+import (
+	"encoding/json"
+	"errors"
+	"jianggujin.com/lvs/internal/util"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Release 与官方 ?mode=json&include=all 结构一致的版本信息
+type Release struct {
+	Version string  `json:"version"` // Go 版本号，例如 "go1.22.0"
+	Stable  bool    `json:"stable"`  // 是否为稳定版本
+	Files   []*File `json:"files"`   // 所有平台与类型的文件
+}
+
+// File 版本发布的文件
+type File struct {
+	Filename string `json:"filename"`
+	Os       string `json:"os"`   // 源码包为空
+	Arch     string `json:"arch"` // 源码包为空
+	Version  string `json:"version"`
+	Sha256   string `json:"sha256"`
+	Size     int64  `json:"size"`
+	Kind     string `json:"kind"` // archive、installer、source
+}
+
+const (
+	KindArchive   = "archive"
+	KindInstaller = "installer"
+	KindSource    = "source"
+)
+
+// parseReleases 优先解析JSON格式，镜像不支持时会忽略查询参数返回下载页面，此时从HTML中提取
+func parseReleases(content []byte) ([]*Release, error) {
+	var releases []*Release
+	if err := json.Unmarshal(content, &releases); err == nil {
+		return releases, nil
+	}
+	releases = parseDownloadPage(string(content))
+	if len(releases) == 0 {
+		return nil, errors.New("no releases found in the download page")
+	}
+	return releases, nil
+}
+
+var downloadRowRegexp = regexp.MustCompile(`<tr[^>]*>\s*<td[^>]*>\s*<a[^>]*>([^<]+)</a>\s*</td>\s*<td[^>]*>([^<]*)</td>\s*<td[^>]*>[^<]*</td>\s*<td[^>]*>[^<]*</td>\s*<td[^>]*>([^<]*)</td>\s*<td[^>]*>\s*<tt>([^<]*)</tt>\s*</td>\s*</tr>`)
+
+// go1.22.3.linux-amd64.tar.gz、go1.22.3.windows-amd64.msi、go1.22.3.src.tar.gz
+var downloadFileRegexp = regexp.MustCompile(`^(go\d+(?:\.\d+){0,2}(?:(?:rc|beta)\d+)?)\.(?:([a-z0-9]+)-([a-z0-9]+)|src)\.`)
+
+// parseDownloadPage 从下载页面的表格中提取文件信息，页面中的系统与架构为展示名称，因此从文件名中解析
+func parseDownloadPage(content string) []*Release {
+	var releases []*Release
+	m := make(map[string]*Release)
+	for _, item := range downloadRowRegexp.FindAllStringSubmatch(content, -1) {
+		name := strings.TrimSpace(item[1])
+		match := downloadFileRegexp.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		release, ok := m[match[1]]
+		if !ok {
+			semver, err := parseSemver(match[1])
+			if err != nil {
+				continue
+			}
+			release = &Release{Version: match[1], Stable: semver.Prerelease() == ""}
+			m[match[1]] = release
+			releases = append(releases, release)
+		}
+		release.Files = append(release.Files, &File{
+			Filename: name,
+			Os:       match[2],
+			Arch:     match[3],
+			Version:  match[1],
+			Sha256:   strings.TrimSpace(item[4]),
+			Size:     parseSize(strings.TrimSpace(item[3])),
+			Kind:     strings.ToLower(strings.TrimSpace(item[2])),
+		})
+	}
+	return releases
+}
+
+// parseSize 解析下载页面中的文件大小，如 68MB、1.2GB，无法解析时返回0
+func parseSize(size string) int64 {
+	units := []struct {
+		suffix string
+		scale  float64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+	for _, unit := range units {
+		if value, ok := strings.CutSuffix(size, unit.suffix); ok {
+			number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return 0
+			}
+			return int64(number * unit.scale)
+		}
+	}
+	return 0
+}
+
+// File 查找指定平台与类型的文件，不存在时返回nil
+func (r *Release) File(goos, goarch, kind string) *File {
+	for _, file := range r.Files {
+		if file.Os == goos && file.Arch == goarch && file.Kind == kind {
+			return file
+		}
+	}
+	return nil
+}
+
+// FormatSize 文件大小的展示形式
+func (f *File) FormatSize() string {
+	return util.FormatBytes(f.Size)
+}
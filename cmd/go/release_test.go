@@ -0,0 +1,48 @@
+package gom
+
+import "testing"
+
+func TestParseReleases(t *testing.T) {
+	content := `[{"version":"go1.22.3","stable":true,"files":[
+{"filename":"go1.22.3.src.tar.gz","os":"","arch":"","version":"go1.22.3","sha256":"aaa","size":27,"kind":"source"},
+{"filename":"go1.22.3.linux-amd64.tar.gz","os":"linux","arch":"amd64","version":"go1.22.3","sha256":"bbb","size":68,"kind":"archive"}]},
+{"version":"go1.23rc1","stable":false,"files":[]}]`
+	releases, err := parseReleases([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != 2 || !releases[0].Stable || releases[1].Stable {
+		t.Fatalf("unexpected releases: %+v", releases)
+	}
+	if file := releases[0].File("linux", "amd64", KindArchive); file == nil || file.Sha256 != "bbb" {
+		t.Fatalf("unexpected archive: %+v", file)
+	}
+}
+
+func TestParseDownloadPage(t *testing.T) {
+	content := `<table class="downloadtable">
+<tr class=" "><td class="filename"><a class="download" href="/dl/go1.22.3.src.tar.gz">go1.22.3.src.tar.gz</a></td><td>Source</td><td></td><td></td><td>26MB</td><td><tt>aaa</tt></td></tr>
+<tr class="highlight "><td class="filename"><a class="download" href="/dl/go1.22.3.linux-amd64.tar.gz">go1.22.3.linux-amd64.tar.gz</a></td><td>Archive</td><td>Linux</td><td>x86-64</td><td>68MB</td><td><tt>bbb</tt></td></tr>
+<tr><td class="filename"><a class="download" href="/dl/go1.23rc1.windows-amd64.msi">go1.23rc1.windows-amd64.msi</a></td><td>Installer</td><td>Windows</td><td>x86-64</td><td>1.5GB</td><td><tt>ccc</tt></td></tr>
+</table>`
+	releases, err := parseReleases([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != 2 || !releases[0].Stable || releases[1].Stable {
+		t.Fatalf("unexpected releases: %+v", releases)
+	}
+	archive := releases[0].File("linux", "amd64", KindArchive)
+	if archive == nil || archive.Sha256 != "bbb" || archive.Size != 68<<20 {
+		t.Fatalf("unexpected archive: %+v", archive)
+	}
+	if source := releases[0].File("", "", KindSource); source == nil {
+		t.Fatal("source file not found")
+	}
+	if installer := releases[1].File("windows", "amd64", KindInstaller); installer == nil || installer.Size != 3<<29 {
+		t.Fatalf("unexpected installer: %+v", installer)
+	}
+	if _, err = parseReleases([]byte("<html></html>")); err == nil {
+		t.Fatal("expected error for a page without releases")
+	}
+}
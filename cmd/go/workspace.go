@@ -0,0 +1,52 @@
+package gom
+
+import (
+	"bufio"
+	"bytes"
+	"jianggujin.com/lvs/cmd/module"
+	"strings"
+)
+
+// WorkspaceFiles 同一目录中go.work优先于go.mod，与其他版本文件一样从当前目录开始逐级向上查找，
+// 子目录中的go.mod优先于上级目录中的go.work，与go命令先在所有上级目录中查找go.work的规则不同
+func (p *Provider) WorkspaceFiles() []*module.WorkspaceFile {
+	return []*module.WorkspaceFile{
+		{Name: "go.work", Parse: parseGoDirectives},
+		{Name: "go.mod", Parse: parseGoDirectives},
+	}
+}
+
+// parseGoDirectives toolchain指令指定确切的版本，go指令作为最低版本约束，如 go 1.21 转换为 >=1.21
+func parseGoDirectives(data []byte) (string, error) {
+	var goVersion, toolchain string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, "//"); index >= 0 {
+			line = line[:index]
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "go":
+			goVersion = fields[1]
+		case "toolchain":
+			// default 表示使用go指令对应的版本
+			if fields[1] != "default" {
+				toolchain = fields[1]
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if toolchain != "" {
+		return toolchain, nil
+	}
+	if goVersion != "" {
+		return ">=" + goVersion, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,21 @@
+package gom
+
+import "testing"
+
+func TestParseGoDirectives(t *testing.T) {
+	tests := map[string]string{
+		"module demo\n\ngo 1.21\n":                                  ">=1.21",
+		"module demo\n\ngo 1.21.0 // minimum\ntoolchain go1.22.3\n": "go1.22.3",
+		"go 1.22\n\ntoolchain default\n\nuse ./a\n":                 ">=1.22",
+		"module demo\n": "",
+	}
+	for content, expected := range tests {
+		version, err := parseGoDirectives([]byte(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != expected {
+			t.Errorf("[%s] expected %s, got %s", content, expected, version)
+		}
+	}
+}
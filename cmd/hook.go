@@ -0,0 +1,85 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/internal/util"
+	"os"
+	"strings"
+)
+
+// hookScripts 目录变化时执行 lvs env --hook，仅读取工作空间版本文件，不会执行 node -v、go version 等命令
+var hookScripts = map[string]string{
+	"bash": `_lvs_hook() {
+  local status=$?
+  if [[ "$PWD" != "$_LVS_LAST_PWD" ]]; then
+    _LVS_LAST_PWD="$PWD"
+    eval "$({{LVS}} env --hook -s bash)"
+  fi
+  return $status
+}
+if [[ ";${PROMPT_COMMAND:-};" != *";_lvs_hook;"* ]]; then
+  PROMPT_COMMAND="_lvs_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`,
+	"zsh": `_lvs_hook() {
+  eval "$({{LVS}} env --hook -s zsh)"
+}
+typeset -ag chpwd_functions
+if (( ! ${chpwd_functions[(I)_lvs_hook]} )); then
+  chpwd_functions=(_lvs_hook $chpwd_functions)
+fi
+_lvs_hook
+`,
+	"fish": `function _lvs_hook --on-variable PWD
+    {{LVS}} env --hook -s fish | source
+end
+_lvs_hook
+`,
+}
+
+type HookCommand struct {
+}
+
+func init() {
+	util.AddCommand(rootCmd, &HookCommand{})
+}
+
+func (command *HookCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hook bash|zsh|fish",
+		Short: "Print the shell hook that switches versions automatically when the directory changes",
+		Long: `Print the shell hook that switches versions automatically when the directory changes.
+Add one of the following lines to the shell config file:
+  bash: eval "$(lvs hook bash)"
+  zsh:  eval "$(lvs hook zsh)"
+  fish: lvs hook fish | source`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		RunE:      command.RunE,
+	}
+	return cmd
+}
+
+func (command *HookCommand) RunE(_ *cobra.Command, args []string) error {
+	script, ok := hookScripts[args[0]]
+	if !ok {
+		return util.WrapErrorMsg("the shell [%s] is not supported, only bash, zsh or fish is allowed", args[0])
+	}
+	fmt.Print(strings.ReplaceAll(script, "{{LVS}}", lvsExecutable()))
+	return nil
+}
+
+// lvsExecutable 当前程序的路径，避免PATH变化后找不到lvs命令
+func lvsExecutable() string {
+	path, err := os.Executable()
+	if err != nil {
+		return "lvs"
+	}
+	if strings.ContainsAny(path, " '\"") {
+		return fmt.Sprintf("%q", path)
+	}
+	return path
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/cmd/module"
 	"jianggujin.com/lvs/internal/config"
 	"jianggujin.com/lvs/internal/install"
 	"jianggujin.com/lvs/internal/util"
@@ -107,6 +108,10 @@ func (command *InstallCommand) RunE(_ *cobra.Command, modules []string) error {
 		envKeyValues[config.EnvLvsHome] = filepath.Dir(targetPath)
 		pathValues = append(pathValues, fmt.Sprintf("%%%s%%", config.EnvLvsHome))
 	}
+	// shims模式下启动器需要位于PATH的最前面
+	if module.ShimMode() {
+		pathValues = append([]string{module.ShimsHome()}, pathValues...)
+	}
 	if err := install.Install(envKeyValues, pathValues); err != nil {
 		return util.WrapErrorMsg("installation failed, please try again").SetErr(err)
 	}
@@ -38,6 +38,9 @@ var rootCmd = &cobra.Command{
 }
 
 func init() {
+	// 补全脚本中注册的命令名称
+	rootCmd.Use = config.Name()
+	rootCmd.PersistentFlags().BoolVar(&config.Offline, "offline", false, "resolve versions from the cached indexes and installed versions only, without network access")
 	timeZone, err := time.LoadLocation("Asia/Shanghai")
 	if err != nil {
 		return
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/cmd/module"
+	"jianggujin.com/lvs/internal/cache"
+	"jianggujin.com/lvs/internal/util"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	util.AddCommand(rootCmd, &MirrorCommand{})
+}
+
+type MirrorCommand struct {
+	Addr        string
+	Dir         string
+	Constraints map[string]*string
+	Lts         bool
+	Prerelease  bool
+	Platforms   []string
+	Newest      int
+	Jobs        int
+}
+
+func (command *MirrorCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mirror",
+		Short: "Serve or synchronize a local mirror with the official layout",
+	}
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve cached archives over HTTP as a LAN mirror",
+		RunE:  command.serveRunE,
+	}
+	flags := serveCmd.Flags()
+	flags.StringVarP(&command.Addr, "addr", "a", ":8080", "address to listen on")
+	flags.StringVarP(&command.Dir, "dir", "d", "", "directory of archives to serve, defaults to the download cache")
+	cmd.AddCommand(serveCmd)
+	cmd.AddCommand(command.syncCommand())
+	return cmd
+}
+
+func (command *MirrorCommand) serveRunE(*cobra.Command, []string) error {
+	server := &mirrorServer{dir: command.Dir, mirrors: module.Mirrors(), checksums: make(map[string]*mirrorChecksum)}
+	if server.dir != "" {
+		info, err := os.Stat(server.dir)
+		if err != nil {
+			return util.WrapErrorMsg("mirror directory [%s] error", server.dir).SetErr(err)
+		}
+		if !info.IsDir() {
+			return util.WrapErrorMsg("[%s] is not a directory", server.dir)
+		}
+	}
+	source := server.dir
+	if source == "" {
+		source = cache.Home()
+	}
+	host := command.Addr
+	if strings.HasPrefix(host, ":") {
+		host = "localhost" + host
+	}
+	fmt.Printf("serving [%s] on http://%s/\n", source, host)
+	for _, name := range server.names() {
+		fmt.Printf("  %s: http://%s/%s/\n", name, host, name)
+	}
+	return http.ListenAndServe(command.Addr, server)
+}
+
+// mirrorChecksum 已计算的文件摘要，文件未变化时直接使用
+type mirrorChecksum struct {
+	size    int64
+	modTime time.Time
+	sha256  string
+}
+
+// mirrorServer 按照官方镜像的目录结构提供归档文件，访问路径为 /<模块名称>/<镜像中的相对路径>
+type mirrorServer struct {
+	dir       string // 为空时使用下载缓存
+	mirrors   map[string]module.Mirror
+	lock      sync.Mutex
+	checksums map[string]*mirrorChecksum
+	indexLock sync.Mutex
+}
+
+func (s *mirrorServer) names() []string {
+	var names []string
+	for name := range s.mirrors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *mirrorServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	name, rel, _ := strings.Cut(strings.TrimPrefix(path.Clean(r.URL.Path), "/"), "/")
+	if name == "" {
+		var buf bytes.Buffer
+		for _, item := range s.names() {
+			_, _ = fmt.Fprintf(&buf, "<a href=\"%s/\">%s/</a><br>\n", item, item)
+		}
+		http.ServeContent(w, r, "index.html", time.Now(), bytes.NewReader(buf.Bytes()))
+		return
+	}
+	mirror, ok := s.mirrors[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if rel == "" {
+		rel = "index.html"
+		// 兼容官方 ?mode=json 格式的版本索引
+		if r.URL.Query().Get("mode") == "json" {
+			rel = "index.json"
+		}
+	}
+	// 目录中已存在的文件优先，如同步的官方索引与签名文件
+	if s.dir != "" {
+		static := filepath.Join(s.dir, name, filepath.FromSlash(rel))
+		if info, err := os.Stat(static); err == nil && info.Mode().IsRegular() {
+			http.ServeFile(w, r, static)
+			return
+		}
+	}
+	archives, err := s.archives(mirror)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, archive := range archives {
+		if mirror.MirrorPath(archive) == rel {
+			http.ServeFile(w, r, archive.Path)
+			return
+		}
+	}
+	if !mirror.MirrorIndex(rel) {
+		http.NotFound(w, r)
+		return
+	}
+	indexes, err := s.indexes(mirror, archives)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if data, ok := indexes[rel]; ok {
+		http.ServeContent(w, r, path.Base(rel), time.Now(), bytes.NewReader(data))
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// archives 每次请求时重新扫描，保证新下载的归档文件可以立即被访问
+func (s *mirrorServer) archives(mirror module.Mirror) ([]*module.MirrorArchive, error) {
+	var archives []*module.MirrorArchive
+	if s.dir == "" {
+		entries, err := cache.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if archive := mirror.ParseMirrorArchive(entry.Name); archive != nil {
+				archive.Path, archive.Size, archive.ModTime, archive.Sha256 = entry.Path, entry.Size, entry.ModTime, entry.Digest
+				archives = append(archives, archive)
+			}
+		}
+		return archives, nil
+	}
+	err := filepath.Walk(s.dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if archive := mirror.ParseMirrorArchive(info.Name()); archive != nil {
+			archive.Path, archive.Size, archive.ModTime = p, info.Size(), info.ModTime()
+			archives = append(archives, archive)
+		}
+		return nil
+	})
+	return archives, err
+}
+
+// indexes 根据磁盘中的归档文件与缓存的上游文件生成索引，不访问网络，Provider会缓存读取的结果，需要串行执行
+func (s *mirrorServer) indexes(mirror module.Mirror, archives []*module.MirrorArchive) (map[string][]byte, error) {
+	if err := s.fillChecksums(archives); err != nil {
+		return nil, err
+	}
+	s.indexLock.Lock()
+	defer s.indexLock.Unlock()
+	return mirror.MirrorIndexes(archives)
+}
+
+func (s *mirrorServer) fillChecksums(archives []*module.MirrorArchive) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, archive := range archives {
+		if archive.Sha256 != "" {
+			continue
+		}
+		item, ok := s.checksums[archive.Path]
+		if !ok || item.size != archive.Size || !item.modTime.Equal(archive.ModTime) {
+			checksum, err := util.FileChecksum(archive.Path)
+			if err != nil {
+				return err
+			}
+			item = &mirrorChecksum{size: archive.Size, modTime: archive.ModTime, sha256: checksum}
+			s.checksums[archive.Path] = item
+		}
+		archive.Sha256 = item.sha256
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"fmt"
+	version2 "github.com/hashicorp/go-version"
+	"github.com/spf13/cobra"
+	"io"
+	"jianggujin.com/lvs/cmd/module"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/util"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// syncTask 需要同步的归档文件
+type syncTask struct {
+	archive  *module.MirrorArchive
+	path     string // 镜像中的相对路径，与上游镜像一致
+	target   string
+	checksum string
+}
+
+func (command *MirrorCommand) syncCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Populate a local mirror directory from the upstream mirrors",
+		RunE:  command.syncRunE,
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&command.Dir, "dir", "d", "", "mirror directory to populate")
+	_ = cmd.MarkFlagRequired("dir")
+	command.Constraints = make(map[string]*string)
+	for name := range config.Modules {
+		constraint := new(string)
+		flags.StringVar(constraint, name, "", fmt.Sprintf("%s version constraint, such as 1.21 or \">=1.21, <1.23\", * for all versions", name))
+		command.Constraints[name] = constraint
+	}
+	flags.BoolVar(&command.Lts, "lts", false, "only synchronize LTS versions")
+	flags.BoolVarP(&command.Prerelease, "prerelease", "p", false, "include prerelease versions")
+	flags.StringSliceVar(&command.Platforms, "platform", []string{runtime.GOOS + "/" + runtime.GOARCH}, "platforms to synchronize, format os/arch")
+	flags.IntVarP(&command.Newest, "newest", "n", 0, "only keep the newest N versions of each minor version, 0 means all")
+	flags.IntVarP(&command.Jobs, "jobs", "j", 4, "number of parallel downloads")
+	return cmd
+}
+
+func (command *MirrorCommand) syncRunE(cmd *cobra.Command, _ []string) error {
+	if command.Jobs < 1 {
+		command.Jobs = 1
+	}
+	mirrors := module.Mirrors()
+	// 指定了版本约束时仅同步对应模块，否则同步全部模块
+	var names []string
+	for name := range command.Constraints {
+		if cmd.Flags().Changed(name) {
+			if _, ok := mirrors[name]; !ok {
+				return util.WrapErrorMsg("module [%s] is not supported on this platform", name)
+			}
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		for name := range mirrors {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	failed := 0
+	for _, name := range names {
+		count, err := command.syncModule(name, mirrors[name])
+		if err != nil {
+			return util.WrapErrorMsg("synchronize [%s] mirror error", name).SetErr(err)
+		}
+		failed += count
+	}
+	if failed > 0 {
+		return util.WrapErrorMsg("%d archives or versions failed to synchronize, run the command again to resume", failed)
+	}
+	return nil
+}
+
+// syncModule 同步单个模块的归档文件并重新生成索引，返回同步失败的文件与跳过的版本数量
+func (command *MirrorCommand) syncModule(name string, mirror module.Mirror) (int, error) {
+	c := module.Lookup(name)
+	platforms := make(map[string]bool)
+	for _, item := range command.Platforms {
+		goos, goarch, ok := strings.Cut(item, "/")
+		platform := ""
+		if ok {
+			platform = mirror.MirrorPlatform(goos, goarch)
+		}
+		if platform == "" {
+			return 0, fmt.Errorf("unsupported platform [%s]", item)
+		}
+		platforms[platform] = true
+	}
+	constraints, err := command.syncConstraint(*command.Constraints[name])
+	if err != nil {
+		return 0, err
+	}
+
+	rawMsg := "[%s] retrieve upstream archives %s"
+	spinner := util.Default(-1, fmt.Sprintf(rawMsg, name, "█"))
+	archives, err := mirror.UpstreamArchives()
+	if err != nil {
+		spinner.Describe(fmt.Sprintf(rawMsg, name, "×"))
+		spinner.Close()
+		return 0, err
+	}
+	archives = command.filterArchives(c, archives, platforms, constraints)
+	spinner.Describe(fmt.Sprintf(rawMsg, name, "√"))
+	spinner.Close()
+
+	tasks, skippedVersions := command.syncTasks(name, mirror, archives)
+	failed, skipped := command.download(name, c, tasks)
+	fmt.Printf("[%s] %d archives synchronized, %d up to date, %d failed, %d versions skipped\n",
+		name, len(tasks)-skipped-len(failed), skipped, len(failed), len(skippedVersions))
+	failed = append(skippedVersions, failed...)
+	for _, err = range failed {
+		fmt.Printf("  %s\n", err)
+	}
+
+	return len(failed), command.writeIndexes(name, mirror, tasks)
+}
+
+// syncConstraint 解析版本约束，与安装命令使用相同的版本范围规则
+func (command *MirrorCommand) syncConstraint(constraint string) (*module.Range, error) {
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "" || constraint == "*" {
+		return nil, nil
+	}
+	return module.ParseRange(constraint)
+}
+
+// filterArchives 按照平台、长期支持、预发布与版本约束过滤，并保留每个次版本最新的N个版本
+func (command *MirrorCommand) filterArchives(c *module.Command, archives []*module.MirrorArchive, platforms map[string]bool, constraints *module.Range) []*module.MirrorArchive {
+	var result []*module.MirrorArchive
+	semvers := make(map[string]*version2.Version)
+	for _, archive := range archives {
+		if !platforms[archive.Platform] || (command.Lts && !archive.Lts) {
+			continue
+		}
+		semver, err := c.Semver(archive.Version)
+		if err != nil || (!command.Prerelease && semver.Prerelease() != "") {
+			continue
+		}
+		if constraints != nil && !constraints.Check(semver) {
+			continue
+		}
+		semvers[archive.Version] = semver
+		result = append(result, archive)
+	}
+	if command.Newest <= 0 {
+		return result
+	}
+
+	minors := make(map[string][]*version2.Version)
+	for _, semver := range semvers {
+		segments := semver.Segments()
+		minor := fmt.Sprintf("%d.%d", segments[0], segments[1])
+		minors[minor] = append(minors[minor], semver)
+	}
+	keep := make(map[string]bool)
+	for _, list := range minors {
+		sort.Sort(sort.Reverse(version2.Collection(list)))
+		for i := 0; i < len(list) && i < command.Newest; i++ {
+			keep[list[i].Original()] = true
+		}
+	}
+	filtered := result[:0]
+	for _, archive := range result {
+		if keep[semvers[archive.Version].Original()] {
+			filtered = append(filtered, archive)
+		}
+	}
+	return filtered
+}
+
+// syncTasks 获取上游发布的摘要，用于校验已存在的文件与新下载的文件，
+// 无法获取摘要的版本会被整体跳过并返回对应的错误，不影响其他版本
+func (command *MirrorCommand) syncTasks(name string, mirror module.Mirror, archives []*module.MirrorArchive) ([]*syncTask, []error) {
+	rawMsg := "[%s] retrieve archive checksums %s"
+	bar := util.Default(int64(len(archives)), fmt.Sprintf(rawMsg, name, "█"))
+	defer bar.Close()
+	tasks := make([]*syncTask, 0, len(archives))
+	var failed []error
+	skipped := make(map[string]bool)
+	for _, archive := range archives {
+		if skipped[archive.Version] {
+			_ = bar.Add(1)
+			continue
+		}
+		checksum, err := mirror.UpstreamChecksum(archive)
+		if err != nil {
+			skipped[archive.Version] = true
+			failed = append(failed, fmt.Errorf("%s: skip version [%s]: %w", archive.Name, archive.Version, err))
+			_ = bar.Add(1)
+			continue
+		}
+		rel := mirror.MirrorPath(archive)
+		tasks = append(tasks, &syncTask{
+			archive:  archive,
+			path:     rel,
+			target:   filepath.Join(command.Dir, name, filepath.FromSlash(rel)),
+			checksum: checksum,
+		})
+		_ = bar.Add(1)
+	}
+	// 版本中已获取摘要的其他归档文件同样跳过，避免版本目录不完整
+	filtered := tasks[:0]
+	for _, task := range tasks {
+		if !skipped[task.archive.Version] {
+			filtered = append(filtered, task)
+		}
+	}
+	if len(failed) > 0 {
+		bar.Describe(fmt.Sprintf(rawMsg, name, "×"))
+	} else {
+		bar.Describe(fmt.Sprintf(rawMsg, name, "√"))
+	}
+	return filtered, failed
+}
+
+// download 并行下载归档文件，摘要一致的已存在文件直接跳过
+func (command *MirrorCommand) download(name string, c *module.Command, tasks []*syncTask) ([]error, int) {
+	bar := util.Default(int64(len(tasks)), fmt.Sprintf("[%s] synchronize archives", name))
+	defer bar.Close()
+	var (
+		lock    sync.Mutex
+		wg      sync.WaitGroup
+		failed  []error
+		skipped int
+	)
+	queue := make(chan *syncTask)
+	for i := 0; i < command.Jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range queue {
+				skip, err := command.syncArchive(c, task)
+				lock.Lock()
+				if err != nil {
+					failed = append(failed, fmt.Errorf("%s: %w", task.archive.Name, err))
+				} else if skip {
+					skipped++
+				}
+				lock.Unlock()
+				_ = bar.Add(1)
+			}
+		}()
+	}
+	for _, task := range tasks {
+		queue <- task
+	}
+	close(queue)
+	wg.Wait()
+	return failed, skipped
+}
+
+// syncArchive 下载单个归档文件，中断后再次同步时从已下载的位置继续
+func (command *MirrorCommand) syncArchive(c *module.Command, task *syncTask) (bool, error) {
+	if util.Exists(task.target) {
+		checksum, err := util.FileChecksum(task.target)
+		if err == nil && strings.EqualFold(checksum, task.checksum) {
+			return true, nil
+		}
+	}
+	dir := filepath.Dir(task.target)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return false, err
+	}
+	archive := task.archive
+	failover := c.Failover()
+	mirrors := failover.Mirrors()
+	if len(mirrors) == 0 {
+		return false, fmt.Errorf("mirror address [%s] is not configured", failover.MirrorKey)
+	}
+	partial, err := util.OpenPartial(dir, strings.TrimSuffix(archive.Name, "."+archive.Ext), archive.Ext, mirrors[0]+task.path)
+	if err != nil {
+		return false, err
+	}
+	resp, _, err := failover.Do(task.path, func(url string) (*http.Request, error) {
+		partial.Retarget(url)
+		return partial.Request()
+	})
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	h := util.NewChecksum()
+	file, err := partial.Open(resp, h)
+	if err != nil {
+		return false, err
+	}
+	_, err = io.Copy(io.MultiWriter(file, h), resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, err
+	}
+	if err = partial.Verify(archive.Name, task.checksum, h); err != nil {
+		return false, err
+	}
+	if err = os.Rename(partial.Path, task.target); err != nil {
+		return false, err
+	}
+	return false, partial.Remove()
+}
+
+// writeIndexes 根据目录中的全部归档文件重新生成索引，版本目录中的文件仅写入本次同步且不存在的版本，
+// 之前同步的上游SHASUMS256.txt及其签名文件保持不变
+func (command *MirrorCommand) writeIndexes(name string, mirror module.Mirror, tasks []*syncTask) error {
+	rawMsg := "[%s] generate mirror indexes %s"
+	spinner := util.Default(-1, fmt.Sprintf(rawMsg, name, "█"))
+	defer spinner.Close()
+	checksums := make(map[string]string)
+	versions := make(map[string]bool)
+	for _, task := range tasks {
+		checksums[task.archive.Name] = task.checksum
+		versions[task.archive.Version] = true
+	}
+	server := &mirrorServer{dir: filepath.Join(command.Dir, name), checksums: make(map[string]*mirrorChecksum)}
+	archives, err := server.archives(mirror)
+	var indexes map[string][]byte
+	if err == nil {
+		for _, archive := range archives {
+			archive.Sha256 = checksums[archive.Name]
+		}
+		indexes, err = server.indexes(mirror, archives)
+	}
+	for rel := range indexes {
+		// 版本目录中已存在任意索引文件时整个版本保持不变，避免摘要文件与签名文件不匹配
+		if dir := path.Dir(rel); dir != "." && util.Exists(filepath.Join(command.Dir, name, filepath.FromSlash(rel))) {
+			versions[dir] = false
+		}
+	}
+	for rel, data := range indexes {
+		if err != nil {
+			break
+		}
+		if dir := path.Dir(rel); dir != "." && !versions[dir] {
+			continue
+		}
+		target := filepath.Join(command.Dir, name, filepath.FromSlash(rel))
+		if err = os.MkdirAll(filepath.Dir(target), os.ModePerm); err == nil {
+			err = os.WriteFile(target, data, 0644)
+		}
+	}
+	if err != nil {
+		spinner.Describe(fmt.Sprintf(rawMsg, name, "×"))
+		return err
+	}
+	spinner.Describe(fmt.Sprintf(rawMsg, name, "√"))
+	return nil
+}
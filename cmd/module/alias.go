@@ -1,6 +1,4 @@
-//go:build (windows && (amd64 || 386 || arm64)) || (linux && (amd64 || arm || armv7l || arm64 || ppc64le || s390x)) || (darwin && (amd64 || arm64))
-
-package node
+package module
 
 import (
 	"fmt"
@@ -14,10 +12,13 @@ import (
 )
 
 func init() {
-	nodeCmd.AddCommand(&AliasCommand{})
+	addCommand(func(c *Command) util.Command {
+		return &AliasCommand{module: c}
+	})
 }
 
 type AliasCommand struct {
+	module *Command
 }
 
 func (command *AliasCommand) Init() *cobra.Command {
@@ -31,14 +32,15 @@ func (command *AliasCommand) Init() *cobra.Command {
 }
 
 func (command *AliasCommand) RunE(_ *cobra.Command, args []string) error {
+	prefix := command.module.Keys().Alias
 	if len(args) == 2 {
-		version := nodeCmd.FixVersion(args[1])
-		if _, err := nodeCmd.Semver(version); err != nil {
-			fmt.Printf("[%s] is not a valid version\n", version)
+		version, err := command.aliasTarget(args[1])
+		if err != nil {
+			fmt.Println(err)
 			return nil
 		}
 		name := strings.ToLower(args[0])
-		config.Set(config.KeyNodeAliasPrefix+name, version)
+		config.Set(prefix+name, version)
 		if err := config.SaveConfig(); err != nil {
 			return util.WrapErrorMsg("failed to save alias [%s: %s]", name, version).SetErr(err)
 		}
@@ -47,7 +49,13 @@ func (command *AliasCommand) RunE(_ *cobra.Command, args []string) error {
 	}
 	if len(args) == 1 {
 		name := strings.ToLower(args[0])
-		version := config.GetString(config.KeyNodeAliasPrefix + name)
+		version := config.GetString(prefix + name)
+		if version == "" {
+			// 未设置时尝试展示内置别名对应的版本范围
+			if resolved, err := command.module.AliasVersion(name); err == nil && resolved != name {
+				version = resolved
+			}
+		}
 		fmt.Printf("%s: %s\n", name, version)
 		return nil
 	}
@@ -59,7 +67,7 @@ func (command *AliasCommand) RunE(_ *cobra.Command, args []string) error {
 	table.SetCenterSeparator("|")
 
 	var keys []string
-	lowerPrefix := strings.ToLower(config.KeyNodeAliasPrefix)
+	lowerPrefix := strings.ToLower(prefix)
 	m := config.Filter(func(s string) bool {
 		if after, ok := strings.CutPrefix(s, lowerPrefix); ok {
 			keys = append(keys, after)
@@ -77,3 +85,17 @@ func (command *AliasCommand) RunE(_ *cobra.Command, args []string) error {
 	table.Render()
 	return nil
 }
+
+// aliasTarget 别名可以指向具体版本或模块的内置别名，内置别名在使用时动态解析，如 lts/*
+func (command *AliasCommand) aliasTarget(target string) (string, error) {
+	if p, ok := command.module.Provider.(AliasProvider); ok {
+		if _, builtin, err := p.BuiltinAlias(target); builtin {
+			return strings.ToLower(target), err
+		}
+	}
+	version := command.module.FixVersion(target)
+	if _, err := command.module.Semver(version); err != nil {
+		return version, fmt.Errorf("[%s] is not a valid version", version)
+	}
+	return version, nil
+}
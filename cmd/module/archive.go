@@ -0,0 +1,51 @@
+package module
+
+import (
+	"fmt"
+	"jianggujin.com/lvs/internal/util"
+	"strings"
+)
+
+// archiveExts 支持解压的归档文件扩展名
+var archiveExts = []string{"tar.gz", "tar.xz", "zip"}
+
+// ParseArchive 根据归档文件名推断版本号，仅接受当前平台的归档文件
+func (c *Command) ParseArchive(name string) (*Download, error) {
+	version := c.ArchiveVersion(name)
+	if version == "" {
+		return nil, fmt.Errorf("unable to infer version from archive name [%s]", name)
+	}
+	download, err := c.ConvertDownload(version)
+	if err != nil {
+		return nil, err
+	}
+	ext, ok := strings.CutPrefix(name, download.BaseName+".")
+	if ok {
+		for _, archiveExt := range archiveExts {
+			if ext == archiveExt {
+				download.Ext = ext
+				return download, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("[%s] is not an archive for the current platform, expected %s.%s", name, download.BaseName, download.Ext)
+}
+
+// ExtractArchive 将归档文件解压到安装目录，归档中的根目录替换为版本号
+func (c *Command) ExtractArchive(home, path string, download *Download, bar util.UnArchive) error {
+	root := c.ArchiveRoot(download)
+	functionFn := func(name string) (string, error) {
+		after, ok := strings.CutPrefix(name, root)
+		if !ok {
+			return "", fmt.Errorf("invalid file name %s", name)
+		}
+		return download.Version + after, nil
+	}
+
+	fn := util.UntarFile
+
+	if "zip" == download.Ext {
+		fn = util.UnzipFile
+	}
+	return fn(path, home, functionFn, bar)
+}
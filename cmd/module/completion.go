@@ -0,0 +1,77 @@
+package module
+
+import (
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/internal/config"
+	"sort"
+	"strings"
+)
+
+// CompletionFunc 补全参数的函数，与cobra.Command.ValidArgsFunction一致
+type CompletionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// Completions 保留以toComplete开头且未在args中出现的候选值，候选值的顺序保持不变
+func Completions(candidates, args []string, toComplete string) []string {
+	exists := make(map[string]bool)
+	for _, arg := range args {
+		exists[arg] = true
+	}
+	var result []string
+	for _, candidate := range candidates {
+		if !exists[candidate] && strings.HasPrefix(candidate, toComplete) {
+			exists[candidate] = true
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+// aliasNames 按照名称排序的别名
+func (c *Command) aliasNames() []string {
+	var names []string
+	for alias := range c.Aliases() {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completeInstalled 补全已安装的版本，multiple为false时仅补全第一个参数，aliases为true时同时补全别名
+func (c *Command) completeInstalled(multiple, aliases bool) CompletionFunc {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if !multiple && len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var installed Collection
+		entries, semvers, _ := c.InstalledVersions()
+		for i, entry := range entries {
+			installed = append(installed, &localVersion{raw: entry.Name(), semver: semvers[i]})
+		}
+		// 新版本在前
+		var candidates []string
+		for _, version := range installed.Sort() {
+			candidates = append(candidates, version.Raw())
+		}
+		if aliases {
+			candidates = append(candidates, c.aliasNames()...)
+		}
+		return Completions(candidates, args, toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+	}
+}
+
+// completeRemote 补全缓存的远程版本与别名，补全时不访问网络，未缓存版本索引时仅补全已安装的版本
+func (c *Command) completeRemote() CompletionFunc {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		config.Offline = true
+		candidates := []string{"latest"}
+		versions, _ := c.ListVersions(nil)
+		for _, version := range versions {
+			candidates = append(candidates, version.Raw())
+		}
+		candidates = append(candidates, c.aliasNames()...)
+		return Completions(candidates, args, toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+	}
+}
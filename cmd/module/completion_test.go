@@ -0,0 +1,18 @@
+package module
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompletions(t *testing.T) {
+	candidates := []string{"v20.11.1", "v18.19.0", "v20.10.0", "default", "v20.11.1"}
+	result := Completions(candidates, []string{"v20.10.0"}, "v20")
+	if strings.Join(result, ",") != "v20.11.1" {
+		t.Errorf("expected v20.11.1, got %v", result)
+	}
+	result = Completions(candidates, nil, "")
+	if strings.Join(result, ",") != "v20.11.1,v18.19.0,v20.10.0,default" {
+		t.Errorf("unexpected completions %v", result)
+	}
+}
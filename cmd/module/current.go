@@ -0,0 +1,45 @@
+package module
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/util"
+)
+
+func init() {
+	addCommand(func(c *Command) util.Command {
+		return &CurrentCommand{module: c}
+	})
+}
+
+type CurrentCommand struct {
+	module *Command
+}
+
+func (command *CurrentCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "current",
+		Short:   "Display the current version being used",
+		Aliases: []string{"v"},
+		Run:     command.Run,
+	}
+	return cmd
+}
+
+func (command *CurrentCommand) Run(*cobra.Command, []string) {
+	ver := command.module.Current()
+
+	if ver == "" {
+		fmt.Printf("there is currently no version in use. You can run '%s %s use x.x.x' to set a version\n", config.Name(), command.module.Name())
+	} else {
+		fmt.Println(ver)
+	}
+	// 展示工作空间与全局默认版本，便于了解版本的来源
+	if version, path, err := command.module.WorkspaceVersion(); err == nil && version != "" {
+		fmt.Printf("workspace: %s (%s)\n", version, path)
+	}
+	if version := config.GetString(command.module.Keys().Global); version != "" {
+		fmt.Printf("global: %s\n", version)
+	}
+}
@@ -0,0 +1,80 @@
+package module
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/invoke"
+	"jianggujin.com/lvs/internal/util"
+	"path/filepath"
+)
+
+func init() {
+	addCommand(func(c *Command) util.Command {
+		return &ExecCommand{module: c}
+	})
+}
+
+type ExecCommand struct {
+	module *Command
+}
+
+func (command *ExecCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "exec",
+		Short:              "Execute commands using the workspace version",
+		DisableFlagParsing: true,
+		RunE:               command.RunE,
+	}
+	return cmd
+}
+
+func (command *ExecCommand) RunE(_ *cobra.Command, args []string) error {
+	version, _, err := command.module.DefaultVersion()
+	if err != nil {
+		return util.WrapError(err)
+	}
+	if version == "" {
+		return util.WrapErrorMsg("valid version not found from workspace or global")
+	}
+	if len(args) < 1 {
+		fmt.Printf("Usage: %s %s exec commands...\n", config.Name(), command.module.Name())
+		return nil
+	}
+	return command.module.Exec(version, args)
+}
+
+// InstalledDir 将别名、版本号或版本范围转换为已安装版本的安装目录
+func (c *Command) InstalledDir(version string) (string, error) {
+	version, err := c.ResolveInstalled(version)
+	if err != nil {
+		return "", err
+	}
+	if _, err := c.Semver(version); err != nil {
+		return "", fmt.Errorf("[%s] is not a valid version", version)
+	}
+	dir := filepath.Join(config.GetPath(c.Keys().Home), version)
+	if !util.Exists(dir) {
+		return "", fmt.Errorf("[%s] not found", version)
+	}
+	return dir, nil
+}
+
+// Exec 使用指定版本执行命令，优先查找版本安装目录中的可执行文件
+func (c *Command) Exec(version string, args []string) error {
+	dir, err := c.InstalledDir(version)
+	if err != nil {
+		return util.WrapError(err)
+	}
+	var arg []string
+	if len(args) > 1 {
+		arg = args[1:]
+	}
+	execPath := filepath.Join(c.BinDir(dir), args[0])
+
+	if util.ExecExists(execPath) {
+		return invoke.GetInvoker().CommandOptions(execPath, arg, invoke.WithStd())
+	}
+
+	return invoke.GetInvoker().CommandOptions(args[0], arg, invoke.WithStd())
+}
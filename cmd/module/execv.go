@@ -0,0 +1,36 @@
+package module
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/util"
+)
+
+func init() {
+	addCommand(func(c *Command) util.Command {
+		return &ExecvCommand{module: c}
+	})
+}
+
+type ExecvCommand struct {
+	module *Command
+}
+
+func (command *ExecvCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "execv",
+		Short:              "Execute commands using the specified version",
+		DisableFlagParsing: true,
+		RunE:               command.RunE,
+	}
+	return cmd
+}
+
+func (command *ExecvCommand) RunE(_ *cobra.Command, args []string) error {
+	if len(args) < 2 {
+		fmt.Printf("Usage: %s %s execv x.x.x commands...\n", config.Name(), command.module.Name())
+		return nil
+	}
+	return command.module.Exec(args[0], args[1:])
+}
@@ -0,0 +1,213 @@
+package module
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/util"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mirrorStateFile 记录各镜像配置上次可用的地址，位于DATA_HOME中
+const mirrorStateFile = "mirror.json"
+
+// probeTimeout 测量镜像延迟的超时时间
+const probeTimeout = 5 * time.Second
+
+var (
+	mirrorLock    sync.Mutex
+	mirrorHealthy map[string]string       // 配置项名称 -> 上次可用的镜像地址
+	mirrorLatency = map[string][]string{} // 配置项名称 -> 按延迟排序的镜像地址，仅在当前进程中有效
+)
+
+// Failover 按照镜像列表依次请求，直到某个镜像返回成功的响应
+type Failover struct {
+	MirrorKey string // 镜像配置项名称
+	ProxyKey  string // 代理配置项名称
+}
+
+func NewFailover(mirrorKey, proxyKey string) *Failover {
+	return &Failover{MirrorKey: mirrorKey, ProxyKey: proxyKey}
+}
+
+// Failover 模块镜像配置对应的故障转移请求
+func (c *Command) Failover() *Failover {
+	return NewFailover(c.Keys().Mirror, c.Keys().Proxy)
+}
+
+// Mirrors 按照MIRROR_STRATEGY排序后的镜像地址
+func (f *Failover) Mirrors() []string {
+	mirrors := config.GetList(f.MirrorKey)
+	if len(mirrors) < 2 {
+		return mirrors
+	}
+	if config.GetString(config.KeyLvsMirrorStrategy) == config.MirrorStrategyLatency {
+		return f.byLatency(mirrors)
+	}
+	mirrorLock.Lock()
+	healthy := loadHealthy()[f.MirrorKey]
+	mirrorLock.Unlock()
+	sorted := make([]string, 0, len(mirrors))
+	for _, mirror := range mirrors {
+		if mirror == healthy {
+			sorted = append([]string{mirror}, sorted...)
+		} else {
+			sorted = append(sorted, mirror)
+		}
+	}
+	return sorted
+}
+
+// Do 依次使用镜像地址与相对路径rel拼接后构造请求，返回成功的响应与实际使用的镜像地址
+func (f *Failover) Do(rel string, newRequest func(url string) (*http.Request, error), opts ...util.HttpClientOption) (*http.Response, string, error) {
+	if config.Offline {
+		return nil, "", ErrOffline
+	}
+	mirrors := f.Mirrors()
+	if len(mirrors) == 0 {
+		return nil, "", fmt.Errorf("mirror address [%s] is not configured", f.MirrorKey)
+	}
+	var errs []string
+	for i, mirror := range mirrors {
+		resp, err := f.do(mirror+rel, newRequest, opts...)
+		if err == nil {
+			if i > 0 {
+				fmt.Fprintf(os.Stderr, "\nmirror [%s] is unavailable, switched to [%s]\n", mirrors[0], mirror)
+			}
+			saveHealthy(f.MirrorKey, mirror)
+			return resp, mirror, nil
+		}
+		if len(mirrors) == 1 {
+			return nil, "", err
+		}
+		errs = append(errs, fmt.Sprintf("[%s] %s", mirror, err))
+	}
+	return nil, "", fmt.Errorf("all mirrors failed: %s", strings.Join(errs, "; "))
+}
+
+func (f *Failover) do(url string, newRequest func(url string) (*http.Request, error), opts ...util.HttpClientOption) (*http.Response, error) {
+	req, err := newRequest(url)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := Do(f.ProxyKey, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// 条件请求的未修改响应同样视为成功
+	notModified := resp.StatusCode == http.StatusNotModified && (req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "")
+	if !notModified && (resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices) {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// Get 使用GET请求获取镜像中的文件
+func (f *Failover) Get(rel string, opts ...util.HttpClientOption) (*http.Response, string, error) {
+	return f.Do(rel, func(url string) (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, url, nil)
+	}, opts...)
+}
+
+// Fetch 读取镜像中文件的完整内容
+func (f *Failover) Fetch(rel string, opts ...util.HttpClientOption) ([]byte, string, error) {
+	resp, mirror, err := f.Get(rel, opts...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	return data, mirror, err
+}
+
+// byLatency 并行测量各镜像的响应时间，无法访问的镜像排在最后
+func (f *Failover) byLatency(mirrors []string) []string {
+	mirrorLock.Lock()
+	sorted, ok := mirrorLatency[f.MirrorKey]
+	mirrorLock.Unlock()
+	if ok {
+		return sorted
+	}
+	latencies := make([]time.Duration, len(mirrors))
+	var wg sync.WaitGroup
+	for i, mirror := range mirrors {
+		wg.Add(1)
+		go func(i int, mirror string) {
+			defer wg.Done()
+			latencies[i] = -1
+			req, err := http.NewRequest(http.MethodHead, mirror, nil)
+			if err != nil {
+				return
+			}
+			start := time.Now()
+			resp, err := Do(f.ProxyKey, req, util.WithTimeout(probeTimeout))
+			if err != nil {
+				return
+			}
+			resp.Body.Close()
+			latencies[i] = time.Since(start)
+		}(i, mirror)
+	}
+	wg.Wait()
+	indexes := make([]int, len(mirrors))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		li, lj := latencies[indexes[i]], latencies[indexes[j]]
+		if li < 0 || lj < 0 {
+			return lj < 0 && li >= 0
+		}
+		return li < lj
+	})
+	sorted = make([]string, len(mirrors))
+	for i, index := range indexes {
+		sorted[i] = mirrors[index]
+	}
+	mirrorLock.Lock()
+	mirrorLatency[f.MirrorKey] = sorted
+	mirrorLock.Unlock()
+	return sorted
+}
+
+func mirrorStatePath() string {
+	return filepath.Join(config.GetPath(config.KeyLvsDataHome), mirrorStateFile)
+}
+
+// loadHealthy 读取上次可用的镜像地址，调用方需持有mirrorLock
+func loadHealthy() map[string]string {
+	if mirrorHealthy == nil {
+		mirrorHealthy = make(map[string]string)
+		if data, err := os.ReadFile(mirrorStatePath()); err == nil {
+			_ = json.Unmarshal(data, &mirrorHealthy)
+		}
+	}
+	return mirrorHealthy
+}
+
+// saveHealthy 记录可用的镜像地址，下次请求时优先使用，记录失败不影响请求
+func saveHealthy(mirrorKey, mirror string) {
+	mirrorLock.Lock()
+	defer mirrorLock.Unlock()
+	healthy := loadHealthy()
+	if healthy[mirrorKey] == mirror {
+		return
+	}
+	healthy[mirrorKey] = mirror
+	data, err := json.Marshal(healthy)
+	if err != nil {
+		return
+	}
+	path := mirrorStatePath()
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err == nil {
+		_ = os.WriteFile(path, data, 0644)
+	}
+}
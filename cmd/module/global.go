@@ -0,0 +1,59 @@
+package module
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/util"
+)
+
+func init() {
+	addCommand(func(c *Command) util.Command {
+		return &GlobalCommand{module: c}
+	})
+}
+
+type GlobalCommand struct {
+	module *Command
+}
+
+func (command *GlobalCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "global",
+		Short:             "Set or display the default version used when the workspace does not declare one",
+		Args:              cobra.MaximumNArgs(1),
+		RunE:              command.RunE,
+		ValidArgsFunction: command.module.completeInstalled(false, true),
+	}
+	return cmd
+}
+
+func (command *GlobalCommand) RunE(_ *cobra.Command, args []string) error {
+	key := command.module.Keys().Global
+	if len(args) == 0 {
+		version := config.GetString(key)
+		if version == "" {
+			fmt.Printf("no global version is set, you can run '%s %s global x.x.x' to set a version\n", config.Name(), command.module.Name())
+			return nil
+		}
+		fmt.Printf("global: %s\n", version)
+		return nil
+	}
+	version := args[0]
+	// none 表示取消全局默认版本
+	if version == "none" {
+		version = ""
+	} else if err := command.module.checkResolvable(version); err != nil {
+		return util.WrapError(err)
+	}
+	config.Set(key, version)
+	if err := config.SaveConfig(); err != nil {
+		return util.WrapErrorMsg("failed to save global version [%s]", version).SetErr(err)
+	}
+	if version == "" {
+		fmt.Println("global version has been unset")
+		return nil
+	}
+	fmt.Printf("global: %s\n", version)
+	return nil
+}
@@ -0,0 +1,142 @@
+package module
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/util"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// indexDir 版本索引缓存目录，位于DATA_HOME中
+const indexDir = "index"
+
+// ErrOffline 离线模式下禁止访问网络
+var ErrOffline = errors.New("network access is disabled in offline mode")
+
+// indexEntry 缓存的版本索引，Data为解析后的结果
+type indexEntry struct {
+	Mirror       string          `json:"mirror"`
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"lastModified,omitempty"`
+	Fetched      time.Time       `json:"fetched"`
+	Data         json.RawMessage `json:"data"`
+}
+
+func indexPath(name string) string {
+	return filepath.Join(config.GetPath(config.KeyLvsDataHome), indexDir, name+".json")
+}
+
+func loadIndex(name string) *indexEntry {
+	data, err := os.ReadFile(indexPath(name))
+	if err != nil {
+		return nil
+	}
+	entry := &indexEntry{}
+	if json.Unmarshal(data, entry) != nil || len(entry.Data) == 0 {
+		return nil
+	}
+	return entry
+}
+
+func (entry *indexEntry) save(name string) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	path := indexPath(name)
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// FetchIndex 获取镜像中的版本索引并将parse解析到v中的结果缓存为DATA_HOME/index/<name>.json，
+// 缓存超过INDEX_TTL后使用ETag或Last-Modified重新验证，离线模式时仅使用缓存
+func (f *Failover) FetchIndex(name, rel string, v any, parse func(data []byte, mirror string) error) error {
+	entry := loadIndex(name)
+	if config.Offline {
+		if entry == nil {
+			return fmt.Errorf("index [%s] is not cached, run the command once without --offline", name)
+		}
+		return json.Unmarshal(entry.Data, v)
+	}
+	mirrors := f.Mirrors()
+	if entry != nil && !contains(mirrors, entry.Mirror) {
+		// 镜像配置已修改，缓存不再适用
+		entry = nil
+	}
+	if entry != nil && time.Since(entry.Fetched) < config.GetDuration(config.KeyLvsIndexTtl) {
+		if err := json.Unmarshal(entry.Data, v); err == nil {
+			return nil
+		}
+		// 缓存格式不兼容时重新获取
+		entry = nil
+	}
+
+	resp, mirror, err := f.Do(rel, func(url string) (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil && url == entry.Mirror+rel {
+			if entry.ETag != "" {
+				req.Header.Set("If-None-Match", entry.ETag)
+			}
+			if entry.LastModified != "" {
+				req.Header.Set("If-Modified-Since", entry.LastModified)
+			}
+		}
+		return req, nil
+	}, util.WithTimeout(30*time.Second))
+	if err != nil {
+		if entry == nil {
+			return err
+		}
+		// 网络不可用时继续使用过期的缓存
+		fmt.Fprintf(os.Stderr, "\nWARNING: failed to refresh index [%s], using the cached one fetched at %s: %s\n", name, entry.Fetched.Format(time.DateTime), err)
+		return json.Unmarshal(entry.Data, v)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		entry.Fetched = time.Now()
+		_ = entry.save(name)
+		return json.Unmarshal(entry.Data, v)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err = parse(content, mirror); err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	entry = &indexEntry{
+		Mirror:       mirror,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Fetched:      time.Now(),
+		Data:         data,
+	}
+	// 缓存写入失败不影响本次结果
+	_ = entry.save(name)
+	return nil
+}
+
+func contains(list []string, item string) bool {
+	for _, value := range list {
+		if value == item {
+			return true
+		}
+	}
+	return false
+}
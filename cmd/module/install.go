@@ -0,0 +1,368 @@
+package module
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"io"
+	"jianggujin.com/lvs/internal/cache"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/util"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	addCommand(func(c *Command) util.Command {
+		return &InstallCommand{module: c}
+	})
+}
+
+type InstallCommand struct {
+	module      *Command
+	Latest      bool
+	Force       bool
+	FromFile    string
+	FromDir     string
+	flags       *pflag.FlagSet
+	stepCount   int
+	currentStep int
+}
+
+func (command *InstallCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "install",
+		Short:             fmt.Sprintf("Install the specified %s version", command.module.Title()),
+		Aliases:           []string{"i"},
+		RunE:              command.RunE,
+		ValidArgsFunction: command.module.completeRemote(),
+	}
+	flags := cmd.Flags()
+	flags.BoolVarP(&command.Latest, "latest", "l", true, "latest version, if false, use the earliest version")
+	flags.BoolVarP(&command.Force, "force", "f", false, "force download of specified version")
+	flags.StringVar(&command.FromFile, "from-file", "", "install from a local archive file without network access")
+	flags.StringVar(&command.FromDir, "from-dir", "", "install the matching archive in a local directory without network access")
+	command.module.InstallFlags(flags)
+	command.flags = flags
+	return cmd
+}
+
+func (command *InstallCommand) RunE(_ *cobra.Command, versions []string) error {
+	if command.FromFile != "" || command.FromDir != "" {
+		return command.installOffline(versions)
+	}
+	if len(versions) == 0 {
+		version, _, err := command.module.DefaultVersion()
+		if err != nil {
+			return util.WrapError(err)
+		}
+		if version == "" {
+			return nil
+		}
+		versions = []string{version}
+	}
+
+	installHome := config.GetPath(command.module.Keys().Home)
+	tempHome := config.GetPath(config.KeyLvsTempHome)
+	version, err := command.module.AliasVersion(versions[0])
+	if err != nil {
+		return util.WrapErrorMsg("resolve alias [%s] error", versions[0]).SetErr(err)
+	}
+	if version == "latest" {
+		command.Latest = true
+		command.Force = false
+	}
+	fmt.Printf("install [%s] start(%s)\n", version, command.describeFlags())
+
+	command.stepCount = 5
+	if !command.Force {
+		command.stepCount = 6
+		var err error
+		version, err = command.findMatchVersion(version)
+		if err != nil {
+			return util.WrapErrorMsg("find match version error").SetErr(err)
+		}
+		version = command.module.FixVersion(version)
+	} else {
+		version = command.module.FixVersion(version)
+		if _, err := command.module.Semver(version); err != nil {
+			return util.WrapErrorMsg("[%s] is not a valid version", version)
+		}
+	}
+
+	if err := command.install(installHome, tempHome, version); err != nil {
+		return util.WrapErrorMsg("install %s error", version).SetErr(err)
+	}
+	fmt.Printf("install %s finish\n", version)
+	command.module.reshimAfterInstall()
+	return nil
+}
+
+// describeFlags 输出所有参数的当前值，如 latest: true force: false
+func (command *InstallCommand) describeFlags() string {
+	var parts []string
+	command.flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Name == "help" {
+			return
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", flag.Name, flag.Value.String()))
+	})
+	return strings.Join(parts, " ")
+}
+
+func (command *InstallCommand) findMatchVersion(version string) (string, error) {
+	rawMsg := "[%d/%d] find matching [%s] version [%s] %s"
+	command.currentStep++
+	currentStep := command.currentStep
+	spinner := util.Default(-1, fmt.Sprintf(rawMsg, currentStep, command.stepCount, version, "?", "█"))
+	defer spinner.Close()
+
+	r, err := ParseRange(version)
+	if err != nil {
+		spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, version, "none", "×"))
+		return "", err
+	}
+	if r.Exact() {
+		version = command.module.FixVersion(version)
+		spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, version, version, "√"))
+		return version, nil
+	}
+	filter := func(version Version) (bool, error) {
+		// 已安装的版本没有远程版本信息，无法使用额外参数过滤
+		if _, local := version.(*localVersion); !local {
+			if ok, e := command.module.InstallFilter(version); !ok || e != nil {
+				return false, e
+			}
+		}
+		se, e := version.Semver()
+		if e != nil {
+			return false, e
+		}
+		return r.Check(se), nil
+	}
+	versions, err := command.module.ListVersions(filter)
+	if err != nil {
+		spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, version, "none", "×"))
+		return "", err
+	}
+	if len(versions) == 0 {
+		spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, version, "none", "×"))
+		return "", errors.New("unable to find a version that matches the criteria")
+	}
+
+	if command.Latest {
+		spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, version, versions[0].Raw(), "√"))
+		return versions[0].Raw(), nil
+	}
+	spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, version, versions[len(versions)-1].Raw(), "√"))
+	return versions[len(versions)-1].Raw(), nil
+}
+
+func (command *InstallCommand) install(home, tempHome, version string) error {
+	download, err := command.module.ConvertDownload(version)
+	if err != nil {
+		return err
+	}
+
+	installed, err := command.checkInstallStatus(filepath.Join(home, version), download)
+	if err != nil {
+		return err
+	}
+	if installed {
+		return nil
+	}
+
+	// 优先使用缓存中的同名归档文件，仅在需要下载时获取摘要
+	entry, err := cache.LookupName(fmt.Sprintf("%s.%s", download.BaseName, download.Ext))
+	if err != nil {
+		return err
+	}
+	if entry != nil {
+		// 不需要获取摘要
+		command.stepCount--
+		if err = command.verifyCache(download, entry); err != nil {
+			fmt.Printf("%v, download again\n", err)
+			// 重新下载需要额外获取摘要、文件信息与下载
+			command.stepCount += 3
+			entry = nil
+		}
+	}
+	if entry == nil {
+		checksum, err := command.checksum(download)
+		if err != nil {
+			return err
+		}
+		if entry, err = command.download(tempHome, download, checksum); err != nil {
+			return err
+		}
+	}
+
+	if err = command.extractArchive(home, entry.Path, download); err != nil {
+		return err
+	}
+	// 安装信息写入失败不影响本次安装
+	_ = command.module.WriteMetadata(version)
+	return nil
+}
+
+func (command *InstallCommand) checkInstallStatus(dir string, download *Download) (bool, error) {
+	rawMsg := "[%d/%d] detecting [%s] installation status %s"
+	command.currentStep++
+	currentStep := command.currentStep
+	spinner := util.Default(-1, fmt.Sprintf(rawMsg, currentStep, command.stepCount, download.Version, "█"))
+	defer spinner.Close()
+	if util.Exists(command.module.Executable(dir)) {
+		spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, download.Version, "√"))
+		return true, nil
+	}
+	spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, download.Version, "×"))
+	if err := os.RemoveAll(dir); err != nil {
+		if !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+func (command *InstallCommand) checksum(download *Download) (string, error) {
+	rawMsg := "[%d/%d] retrieve [%s] archive checksum %s"
+	command.currentStep++
+	currentStep := command.currentStep
+	spinner := util.Default(-1, fmt.Sprintf(rawMsg, currentStep, command.stepCount, download.Version, "█"))
+	defer spinner.Close()
+	checksum, err := command.module.Checksum(download)
+	if err != nil {
+		spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, download.Version, "×"))
+		return "", err
+	}
+	spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, download.Version, "√"))
+	return checksum, nil
+}
+
+// fetchArchive 按照镜像列表依次请求归档文件，切换镜像时沿用已下载的内容
+func (command *InstallCommand) fetchArchive(download *Download, partial *util.Partial, consumer func(*http.Response, string) error) error {
+	rawMsg := "[%d/%d] retrieve [%s] archive file information %s"
+	command.currentStep++
+	currentStep := command.currentStep
+	spinner := util.Default(-1, fmt.Sprintf(rawMsg, currentStep, command.stepCount, download.Version, "█"))
+	defer spinner.Close()
+
+	resp, mirror, err := command.module.Failover().Do(command.module.DownloadPath(download), func(url string) (*http.Request, error) {
+		partial.Retarget(url)
+		return partial.Request()
+	})
+	if err != nil {
+		spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, download.Version, "×"))
+		return err
+	}
+	defer resp.Body.Close()
+
+	spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, download.Version, "√"))
+	spinner.Close()
+	return consumer(resp, mirror)
+}
+
+// verifyCache 使用存入缓存时已校验的摘要重新校验缓存文件，文件损坏时删除该缓存
+func (command *InstallCommand) verifyCache(download *Download, entry *cache.Entry) error {
+	rawMsg := "[%d/%d] retrieve [%s] archive file from cache %s"
+	command.currentStep++
+	currentStep := command.currentStep
+	spinner := util.Default(-1, fmt.Sprintf(rawMsg, currentStep, command.stepCount, download.Version, "√"))
+	spinner.Close()
+
+	if err := command.verifyArchive(download, entry.Path, entry.Digest); err != nil {
+		_ = cache.Remove(entry)
+		return fmt.Errorf("cached archive is corrupted and has been removed: %w", err)
+	}
+	return nil
+}
+
+// verifyArchive 计算本地归档文件的摘要并与期望摘要比较
+func (command *InstallCommand) verifyArchive(download *Download, path, checksum string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	rawMsg := "[%d/%d] verify [%s] archive file"
+	command.currentStep++
+	currentStep := command.currentStep
+	bar := util.DefaultBytes(info.Size(), fmt.Sprintf(rawMsg, currentStep, command.stepCount, download.Version))
+	defer bar.Close()
+	h := util.NewChecksum()
+	if _, err = io.Copy(io.MultiWriter(bar, h), file); err != nil {
+		return err
+	}
+	return util.VerifyChecksum(filepath.Base(path), checksum, h)
+}
+
+func (command *InstallCommand) download(tempHome string, download *Download, checksum string) (*cache.Entry, error) {
+	if err := os.MkdirAll(tempHome, os.ModePerm); err != nil {
+		return nil, err
+	}
+	// 清理异常退出后遗留的过期文件
+	if err := util.CleanPartials(tempHome, config.GetDuration(config.KeyLvsTempExpire)); err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("%s.%s", download.BaseName, download.Ext)
+	mirrors := command.module.Failover().Mirrors()
+	if len(mirrors) == 0 {
+		return nil, fmt.Errorf("mirror address [%s] is not configured", command.module.Keys().Mirror)
+	}
+	partial, err := util.OpenPartial(tempHome, download.BaseName, download.Ext, mirrors[0]+command.module.DownloadPath(download))
+	if err != nil {
+		return nil, err
+	}
+	// 边下载边计算摘要，校验失败时不进行解压
+	h := util.NewChecksum()
+	err = command.fetchArchive(download, partial, func(resp *http.Response, mirror string) error {
+		file, err := partial.Open(resp, h)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		rawMsg := "[%d/%d] download [%s] archive file from [%s]"
+		if partial.Offset > 0 {
+			rawMsg = "[%d/%d] resume download [%s] archive file from [%s]"
+		}
+		command.currentStep++
+		currentStep := command.currentStep
+		bar := util.DefaultBytes(partial.Size, fmt.Sprintf(rawMsg, currentStep, command.stepCount, download.Version, mirror))
+		defer bar.Close()
+		if partial.Offset > 0 {
+			_ = bar.Set64(partial.Offset)
+		}
+
+		_, err = io.Copy(io.MultiWriter(file, bar, h), resp.Body)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = partial.Verify(name, checksum, h); err != nil {
+		return nil, err
+	}
+	// 校验通过的文件移动到缓存中，未通过校验或下载中断的文件保留在临时目录中，下次安装时续传
+	entry, err := cache.Store(checksum, name, partial.Path)
+	if err != nil {
+		return nil, err
+	}
+	return entry, partial.Remove()
+}
+
+func (command *InstallCommand) extractArchive(home, tempPath string, download *Download) error {
+	rawMsg := "[%d/%d] extract [%s] archive files"
+	command.currentStep++
+	currentStep := command.currentStep
+	bar := util.DefaultBytes(-1, fmt.Sprintf(rawMsg, currentStep, command.stepCount, download.Version))
+	defer bar.Close()
+	return command.module.ExtractArchive(home, tempPath, download, bar)
+}
@@ -0,0 +1,198 @@
+package module
+
+import (
+	"errors"
+	"fmt"
+	version2 "github.com/hashicorp/go-version"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/util"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archive 本地归档文件
+type archive struct {
+	path     string
+	download *Download
+	semver   *version2.Version
+}
+
+func (a *archive) Raw() string {
+	return a.download.Version
+}
+
+func (a *archive) Semver() (*version2.Version, error) {
+	return a.semver, nil
+}
+
+// installOffline 从本地归档文件安装，不访问网络
+func (command *InstallCommand) installOffline(versions []string) error {
+	if command.FromFile != "" && command.FromDir != "" {
+		return util.WrapErrorMsg("--from-file and --from-dir cannot be used at the same time")
+	}
+	version := "latest"
+	if len(versions) > 0 {
+		version = versions[0]
+	} else {
+		// 与在线安装一致，未指定版本时使用工作空间或全局默认版本，--from-file的文件也需要与之匹配
+		workspace, _, err := command.module.DefaultVersion()
+		if err != nil {
+			return util.WrapError(err)
+		}
+		if workspace != "" {
+			version = workspace
+		}
+	}
+	version, err := command.module.AliasVersion(version)
+	if err != nil {
+		return util.WrapErrorMsg("resolve alias [%s] error", version).SetErr(err)
+	}
+	fmt.Printf("install [%s] start(%s)\n", version, command.describeFlags())
+
+	command.stepCount = 5
+	local, err := command.findArchive(version)
+	if err != nil {
+		return util.WrapErrorMsg("find match archive error").SetErr(err)
+	}
+	if err = command.installArchive(config.GetPath(command.module.Keys().Home), local); err != nil {
+		return util.WrapErrorMsg("install %s error", local.download.Version).SetErr(err)
+	}
+	fmt.Printf("install %s finish\n", local.download.Version)
+	command.module.reshimAfterInstall()
+	return nil
+}
+
+// parseArchive 根据文件名推断版本号，仅接受当前平台的归档文件
+func (command *InstallCommand) parseArchive(path string) (*archive, error) {
+	download, err := command.module.ParseArchive(filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	semver, err := command.module.Semver(download.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &archive{path: path, download: download, semver: semver}, nil
+}
+
+func (command *InstallCommand) findArchive(version string) (*archive, error) {
+	rawMsg := "[%d/%d] find matching [%s] archive [%s] %s"
+	command.currentStep++
+	currentStep := command.currentStep
+	spinner := util.Default(-1, fmt.Sprintf(rawMsg, currentStep, command.stepCount, version, "?", "█"))
+	defer spinner.Close()
+
+	var archives Collection
+	if command.FromFile != "" {
+		local, err := command.parseArchive(command.FromFile)
+		if err != nil {
+			spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, version, "none", "×"))
+			return nil, err
+		}
+		archives = append(archives, local)
+	} else {
+		entries, err := os.ReadDir(command.FromDir)
+		if err != nil {
+			spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, version, "none", "×"))
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			// 目录中可能包含其他平台的归档文件或摘要文件，忽略即可
+			if local, err := command.parseArchive(filepath.Join(command.FromDir, entry.Name())); err == nil {
+				archives = append(archives, local)
+			}
+		}
+	}
+
+	r, err := ParseRange(version)
+	if err != nil {
+		spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, version, "none", "×"))
+		return nil, err
+	}
+	filter := func(local Version) (bool, error) {
+		if r.Exact() {
+			return local.Raw() == command.module.FixVersion(version), nil
+		}
+		se, _ := local.Semver()
+		return r.Check(se), nil
+	}
+	archives, err = archives.Sort().Filter(filter)
+	if err != nil {
+		spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, version, "none", "×"))
+		return nil, err
+	}
+	if len(archives) == 0 {
+		spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, version, "none", "×"))
+		return nil, errors.New("unable to find an archive that matches the criteria")
+	}
+	local := archives[0].(*archive)
+	if !command.Latest {
+		local = archives[len(archives)-1].(*archive)
+	}
+	spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, version, filepath.Base(local.path), "√"))
+	return local, nil
+}
+
+func (command *InstallCommand) installArchive(home string, local *archive) error {
+	installed, err := command.checkInstallStatus(filepath.Join(home, local.download.Version), local.download)
+	if err != nil {
+		return err
+	}
+	if installed {
+		return nil
+	}
+
+	rawMsg := "[%d/%d] retrieve [%s] archive checksum %s"
+	command.currentStep++
+	currentStep := command.currentStep
+	spinner := util.Default(-1, fmt.Sprintf(rawMsg, currentStep, command.stepCount, local.download.Version, "█"))
+	checksum, err := command.localChecksum(local)
+	if err != nil {
+		spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, local.download.Version, "×"))
+		spinner.Close()
+		return err
+	}
+	spinner.Describe(fmt.Sprintf(rawMsg, currentStep, command.stepCount, local.download.Version, "√"))
+	spinner.Close()
+
+	if err = command.verifyArchive(local.download, local.path, checksum); err != nil {
+		return err
+	}
+	if err = command.extractArchive(home, local.path, local.download); err != nil {
+		return err
+	}
+	// 安装信息写入失败不影响本次安装
+	_ = command.module.WriteMetadata(local.download.Version)
+	return nil
+}
+
+// localChecksum 优先读取同名的.sha256文件，其次读取同目录下的SHASUMS256.txt，
+// 均不存在时使用缓存的远程索引中的摘要，不访问网络
+func (command *InstallCommand) localChecksum(local *archive) (string, error) {
+	path := local.path
+	name := filepath.Base(path)
+	if data, err := os.ReadFile(path + ".sha256"); err == nil {
+		// 文件内容可能仅包含摘要，也可能是 sha256sum 的输出格式
+		if fields := strings.Fields(string(data)); len(fields) > 0 {
+			return fields[0], nil
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(filepath.Dir(path), "SHASUMS256.txt")); err == nil {
+		if checksum := util.ParseChecksums(data)[name]; checksum != "" {
+			return checksum, nil
+		}
+	}
+	offline := config.Offline
+	config.Offline = true
+	checksum, err := command.module.Checksum(local.download)
+	config.Offline = offline
+	if err == nil {
+		return checksum, nil
+	}
+	return "", fmt.Errorf("checksum of [%s] not found, please provide [%s.sha256] or [SHASUMS256.txt] in the same directory, "+
+		"the cached remote index is also unavailable: %w", name, name, err)
+}
@@ -0,0 +1,116 @@
+package module
+
+import (
+	"fmt"
+	"github.com/hashicorp/go-version"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/internal/util"
+	"os"
+	"sort"
+	"time"
+)
+
+func init() {
+	addCommand(func(c *Command) util.Command {
+		return &ListCommand{module: c}
+	})
+}
+
+type ListCommand struct {
+	module *Command
+	All    bool
+}
+
+func (command *ListCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   fmt.Sprintf("List all available versions of %s", command.module.Title()),
+		Aliases: []string{"ls"},
+		RunE:    command.RunE,
+	}
+	flags := cmd.Flags()
+	flags.BoolVarP(&command.All, "all", "a", false, "list all available versions")
+	return cmd
+}
+
+func (command *ListCommand) RunE(_ *cobra.Command, consts []string) error {
+	var constraints version.Constraints
+
+	if len(consts) > 0 {
+		var err error
+		if constraints, err = version.NewConstraint(consts[0]); err != nil {
+			return util.WrapErrorMsg("parse version constraint error").SetErr(err)
+		}
+	}
+	current := command.module.Current()
+
+	entries, versions, err := command.module.InstalledVersions()
+	if err != nil {
+		return util.WrapErrorMsg("list local installed version error").SetErr(err)
+	}
+	installed := make(map[string]time.Time)
+	for _, entry := range entries {
+		if info, _ := entry.Info(); info != nil {
+			if modTime := info.ModTime(); !modTime.IsZero() {
+				installed[entry.Name()] = modTime
+			}
+		}
+	}
+	if !command.All {
+		sort.Sort(version.Collection(versions))
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"", "Version", "Time"})
+		table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+		table.SetAlignment(tablewriter.ALIGN_CENTER)
+		table.SetCenterSeparator("|")
+		for i := len(versions) - 1; i >= 0; i-- {
+			ver := versions[i]
+			if constraints != nil && !constraints.Check(ver) {
+				continue
+			}
+			raw := command.module.RawVersion(ver)
+			row := []string{"", raw, installed[raw].Format(time.DateTime)}
+			if row[1] == current {
+				row[0] = " * "
+			}
+			table.Append(row)
+		}
+		table.Render()
+		return nil
+	}
+
+	list, err := command.module.ListVersions(func(ver Version) (bool, error) {
+		if constraints != nil {
+			v, _ := ver.Semver()
+			if v != nil && !constraints.Check(v) {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return util.WrapErrorMsg("list all available versions error").SetErr(err)
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(append([]string{"", "Version"}, command.module.Columns()...))
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetCenterSeparator("|")
+	for _, ver := range list {
+		var row []string
+		if _, local := ver.(*localVersion); local {
+			row = append([]string{"", ver.Raw()}, make([]string, len(command.module.Columns()))...)
+		} else {
+			row = append([]string{"", ver.Raw()}, command.module.Row(ver)...)
+		}
+		if ver.Raw() == current {
+			row[0] = " * "
+		} else if _, ok := installed[ver.Raw()]; ok {
+			row[0] = " + "
+		}
+		table.Append(row)
+	}
+	table.Render()
+	return nil
+}
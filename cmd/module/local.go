@@ -0,0 +1,98 @@
+package module
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/util"
+	"os"
+	"path/filepath"
+)
+
+func init() {
+	addCommand(func(c *Command) util.Command {
+		return &LocalCommand{module: c}
+	})
+}
+
+type LocalCommand struct {
+	module       *Command
+	ToolVersions bool
+}
+
+func (command *LocalCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "local",
+		Short:             "Set or display the workspace version of the current directory",
+		Args:              cobra.MaximumNArgs(1),
+		RunE:              command.RunE,
+		ValidArgsFunction: command.module.completeInstalled(false, true),
+	}
+	cmd.Flags().BoolVarP(&command.ToolVersions, "tool-versions", "t", false, "write the version to "+ToolVersions)
+	return cmd
+}
+
+func (command *LocalCommand) RunE(_ *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		version, path, err := command.module.WorkspaceVersion()
+		if err != nil {
+			return util.WrapError(err)
+		}
+		if version == "" {
+			fmt.Printf("no workspace version found, you can run '%s %s local x.x.x' to set a version\n", config.Name(), command.module.Name())
+			return nil
+		}
+		fmt.Printf("%s: %s\n", path, version)
+		return nil
+	}
+	version := args[0]
+	if err := command.module.checkResolvable(version); err != nil {
+		return util.WrapError(err)
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return util.WrapError(err)
+	}
+	// 当前目录已存在 .tool-versions 时更新该文件，避免同时存在两个声明
+	path := filepath.Join(dir, ToolVersions)
+	if command.ToolVersions || util.Exists(path) {
+		err = WriteToolVersion(path, command.module.Name(), version)
+	} else {
+		path = filepath.Join(dir, command.module.Name()+config.KeyWorkspaceSuffix)
+		err = os.WriteFile(path, []byte(version+"\n"), 0644)
+	}
+	if err != nil {
+		return util.WrapErrorMsg("write workspace version error").SetErr(err)
+	}
+	fmt.Printf("%s: %s\n", path, version)
+	return nil
+}
+
+// checkResolvable 校验版本能够匹配已安装或远程的版本
+func (c *Command) checkResolvable(version string) error {
+	resolved, err := c.AliasVersion(version)
+	if err != nil {
+		return err
+	}
+	r, err := ParseRange(resolved)
+	if err != nil {
+		return err
+	}
+	if _, installed, err := c.InstalledVersions(); err == nil && r.Select(installed, true) != nil {
+		return nil
+	}
+	versions, err := c.ListVersions(func(v Version) (bool, error) {
+		semver, e := v.Semver()
+		if e != nil {
+			return false, e
+		}
+		return r.Check(semver), nil
+	})
+	if err != nil {
+		return fmt.Errorf("[%s] is not installed and the remote versions are unavailable: %w", version, err)
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("[%s] does not match any installed or remote version", version)
+	}
+	return nil
+}
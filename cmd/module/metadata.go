@@ -0,0 +1,51 @@
+package module
+
+import (
+	"encoding/json"
+	"jianggujin.com/lvs/internal/config"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// metadataName 安装目录中记录安装信息的文件
+const metadataName = ".lvs.json"
+
+// Metadata 安装时记录的版本信息，远程索引不可用时使用
+type Metadata struct {
+	Version   string            `json:"version"`
+	Installed time.Time         `json:"installed"`
+	Labels    map[string]string `json:"labels,omitempty"` // 模块额外记录的信息，如node的LTS代号
+}
+
+// MetadataProvider 可选接口，安装完成后记录模块额外的版本信息，不能访问网络
+type MetadataProvider interface {
+	InstallLabels(version, dir string) map[string]string
+}
+
+// WriteMetadata 在版本的安装目录中记录安装信息
+func (c *Command) WriteMetadata(version string) error {
+	dir := filepath.Join(config.GetPath(c.Keys().Home), version)
+	metadata := &Metadata{Version: version, Installed: time.Now()}
+	if p, ok := c.Provider.(MetadataProvider); ok {
+		metadata.Labels = p.InstallLabels(version, dir)
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, metadataName), data, 0644)
+}
+
+// ReadMetadata 读取安装目录中的安装信息，不存在时返回nil
+func ReadMetadata(dir string) *Metadata {
+	data, err := os.ReadFile(filepath.Join(dir, metadataName))
+	if err != nil {
+		return nil
+	}
+	metadata := &Metadata{}
+	if json.Unmarshal(data, metadata) != nil {
+		return nil
+	}
+	return metadata
+}
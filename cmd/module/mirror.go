@@ -0,0 +1,38 @@
+package module
+
+import "time"
+
+// MirrorArchive 镜像中的归档文件，可以是任意平台
+type MirrorArchive struct {
+	Version  string    // 原始版本号，如 go1.22.0、v20.9.0
+	Platform string    // 平台标识，如 linux-amd64、linux-x64
+	Ext      string    // 扩展名，如 tar.gz
+	Name     string    // 文件名
+	Path     string    // 本地文件路径
+	Size     int64     // 文件大小
+	ModTime  time.Time // 修改时间
+	Sha256   string    // 文件摘要
+	Lts      bool      // 是否为长期支持版本，不区分长期支持版本的模块始终为true
+}
+
+// Mirror 按照官方镜像的目录结构提供归档文件与版本索引，Provider实现该接口后即可使用镜像相关命令
+type Mirror interface {
+	ParseMirrorArchive(string) *MirrorArchive                  // 解析任意平台的归档文件名，无法解析时返回nil
+	MirrorPath(*MirrorArchive) string                          // 归档文件在镜像中的相对路径
+	MirrorIndexes([]*MirrorArchive) (map[string][]byte, error) // 根据归档文件生成索引文件，键为镜像中的相对路径，不能访问网络
+	MirrorIndex(rel string) bool                               // 相对路径是否为MirrorIndexes可能生成的索引文件
+	MirrorPlatform(goos, goarch string) string                 // GOOS与GOARCH转换为平台标识，不支持时返回空
+	UpstreamArchives() ([]*MirrorArchive, error)               // 上游镜像中所有平台的归档文件
+	UpstreamChecksum(*MirrorArchive) (string, error)           // 上游镜像发布的归档文件摘要
+}
+
+// Mirrors 已注册的支持镜像的模块，键为模块名称
+func Mirrors() map[string]Mirror {
+	mirrors := make(map[string]Mirror)
+	for _, c := range commands {
+		if mirror, ok := c.Provider.(Mirror); ok {
+			mirrors[c.Name()] = mirror
+		}
+	}
+	return mirrors
+}
@@ -0,0 +1,308 @@
+package module
+
+import (
+	"fmt"
+	version2 "github.com/hashicorp/go-version"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/util"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Keys 模块相关配置项名称
+type Keys struct {
+	Home    string // 程序安装目录
+	Symlink string // 软链的文件位置
+	Mirror  string // 镜像地址
+	Proxy   string // 代理配置
+	Alias   string // 版本别名前缀
+	Global  string // 全局默认版本
+}
+
+// Version 远程版本信息
+type Version interface {
+	Raw() string                        // 原始版本号，如 go1.22.0、v20.9.0
+	Semver() (*version2.Version, error) // 语义化版本
+}
+
+// Download 归档文件下载信息
+type Download struct {
+	Version  string
+	BaseName string
+	Ext      string
+}
+
+// Provider 工具链提供者，新增工具链仅需实现该接口即可获得全部版本管理命令
+type Provider interface {
+	Name() string  // 模块名称，同时作为命令名称与可执行文件名称
+	Title() string // 展示名称
+	Keys() *Keys   // 配置项名称
+
+	Current() string                           // 当前正在使用的版本
+	RemoteVersions() (Collection, error)       // 远程版本索引，仅包含当前平台可用的版本
+	Columns() []string                         // 远程版本列表额外展示的列
+	Row(Version) []string                      // 远程版本列表额外展示的列值
+	InstallFlags(*pflag.FlagSet)               // 安装命令额外的参数
+	InstallFilter(Version) (bool, error)       // 安装时根据额外参数过滤远程版本
+	Semver(string) (*version2.Version, error)  // 解析版本号
+	FixVersion(string) string                  // 补全版本号前缀
+	RawVersion(*version2.Version) string       // 语义化版本转换为原始版本号
+	ConvertDownload(string) (*Download, error) // 版本号转换为当前平台的下载信息
+	ArchiveVersion(string) string              // 从归档文件名中解析版本号，无法解析时返回空
+	DownloadPath(*Download) string             // 归档文件在镜像中的相对路径
+	Checksum(*Download) (string, error)        // 归档文件官方发布的SHA-256摘要
+	ArchiveRoot(*Download) string              // 归档文件中的根目录名称
+	BinDir(string) string                      // 安装目录中可执行文件所在目录
+}
+
+// AliasProvider 可选接口，模块内置的动态别名，如node的lts/*，builtin为false表示不是内置别名
+type AliasProvider interface {
+	BuiltinAlias(alias string) (version string, builtin bool, err error)
+}
+
+// Command 基于Provider的版本管理命令
+type Command struct {
+	Provider
+	command *cobra.Command
+}
+
+var factories []func(*Command) util.Command
+
+var commands []*Command
+
+// addCommand 注册子命令，所有模块共用
+func addCommand(factory func(*Command) util.Command) {
+	factories = append(factories, factory)
+}
+
+func Init(rootCmd *cobra.Command, provider Provider) *Command {
+	c := &Command{
+		Provider: provider,
+		command: &cobra.Command{
+			Use:   provider.Name(),
+			Short: provider.Title() + " version management",
+		},
+	}
+	for _, factory := range factories {
+		util.AddCommand(c.command, factory(c))
+	}
+	rootCmd.AddCommand(c.command)
+	commands = append(commands, c)
+	return c
+}
+
+// Commands 已注册的所有模块
+func Commands() []*Command {
+	return commands
+}
+
+// Lookup 根据名称查找已注册的模块，不存在时返回nil
+func Lookup(name string) *Command {
+	for _, c := range commands {
+		if c.Name() == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (c *Command) Get(url string, opts ...util.HttpClientOption) (*http.Response, error) {
+	return Get(c.Keys().Proxy, url, opts...)
+}
+
+func (c *Command) Do(req *http.Request, opts ...util.HttpClientOption) (*http.Response, error) {
+	return Do(c.Keys().Proxy, req, opts...)
+}
+
+// NewHttpClient 使用模块代理配置创建客户端，模块未配置代理时使用全局代理
+func NewHttpClient(proxyKey string, opts ...util.HttpClientOption) *http.Client {
+	ops := append([]util.HttpClientOption{util.WithProxyStr(config.GetStringWithDefault(proxyKey, config.GetString(config.KeyLvsProxy)))}, opts...)
+	return util.NewHttpClient(ops...)
+}
+
+func Get(proxyKey, url string, opts ...util.HttpClientOption) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return Do(proxyKey, req, opts...)
+}
+
+// Do 发送自定义请求，统一设置User-Agent
+func Do(proxyKey string, req *http.Request, opts ...util.HttpClientOption) (*http.Response, error) {
+	if config.Offline {
+		return nil, ErrOffline
+	}
+	req.Header.Set("User-Agent", fmt.Sprintf("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36 LVS/%s", config.BuildVersion))
+	return NewHttpClient(proxyKey, opts...).Do(req)
+}
+
+// Executable 安装目录中主程序的路径
+func (c *Command) Executable(dir string) string {
+	name := c.Name()
+	if runtime.GOOS == "windows" {
+		name = name + ".exe"
+	}
+	return filepath.Join(c.BinDir(dir), name)
+}
+
+// AliasVersion 将别名转换为对应的版本号或版本范围，别名可以指向模块的内置别名
+func (c *Command) AliasVersion(version string) (string, error) {
+	version = config.GetStringWithDefault(c.Keys().Alias+version, version)
+	if p, ok := c.Provider.(AliasProvider); ok {
+		if resolved, builtin, err := p.BuiltinAlias(version); builtin || err != nil {
+			return resolved, err
+		}
+	}
+	return version, nil
+}
+
+// Aliases 获取所有版本别名，键为别名
+func (c *Command) Aliases() map[string]string {
+	aliases := make(map[string]string)
+	lowerPrefix := strings.ToLower(c.Keys().Alias)
+	for key, value := range config.Filter(func(s string) bool {
+		return strings.HasPrefix(s, lowerPrefix)
+	}) {
+		aliases[strings.TrimPrefix(key, lowerPrefix)] = value
+	}
+	return aliases
+}
+
+// ListVersions 获取远程版本并进行过滤，离线模式下同时包含已安装的版本
+func (c *Command) ListVersions(filter func(Version) (bool, error)) (Collection, error) {
+	versions, err := c.RemoteVersions()
+	if err != nil && !config.Offline {
+		return nil, err
+	}
+	if config.Offline {
+		locals, e := c.localVersions(versions)
+		if e != nil {
+			return nil, e
+		}
+		if len(versions) == 0 && len(locals) == 0 && err != nil {
+			return nil, err
+		}
+		versions = append(versions, locals...).Sort()
+	}
+	return versions.Filter(filter)
+}
+
+// localVersion 已安装但不在远程版本索引中的版本
+type localVersion struct {
+	raw    string
+	semver *version2.Version
+}
+
+func (v *localVersion) Raw() string {
+	return v.raw
+}
+
+func (v *localVersion) Semver() (*version2.Version, error) {
+	return v.semver, nil
+}
+
+func (c *Command) localVersions(remote Collection) (Collection, error) {
+	exists := make(map[string]bool)
+	for _, version := range remote {
+		exists[version.Raw()] = true
+	}
+	dirs, semvers, err := c.InstalledVersions()
+	if err != nil {
+		return nil, err
+	}
+	var versions Collection
+	for i, dir := range dirs {
+		if !exists[dir.Name()] && util.Exists(c.Executable(filepath.Join(config.GetPath(c.Keys().Home), dir.Name()))) {
+			versions = append(versions, &localVersion{raw: dir.Name(), semver: semvers[i]})
+		}
+	}
+	return versions, nil
+}
+
+// InstalledVersions 获取本地已安装的版本
+func (c *Command) InstalledVersions() ([]os.DirEntry, []*version2.Version, error) {
+	entries, err := os.ReadDir(config.GetPath(c.Keys().Home))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+	var dirs []os.DirEntry
+	var versions []*version2.Version
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if ver, _ := c.Semver(entry.Name()); ver != nil {
+			dirs = append(dirs, entry)
+			versions = append(versions, ver)
+		}
+	}
+	return dirs, versions, nil
+}
+
+type Collection []Version
+
+func (v Collection) Len() int {
+	return len(v)
+}
+
+func (v Collection) Less(i, j int) bool {
+	vi, _ := v[i].Semver()
+	vj, _ := v[j].Semver()
+	return vi.GreaterThan(vj)
+}
+
+func (v Collection) Swap(i, j int) {
+	v[i], v[j] = v[j], v[i]
+}
+
+// Sort 过滤无法解析的版本并按照版本号倒序排列
+func (v Collection) Sort() Collection {
+	result := make(Collection, 0, len(v))
+	for _, version := range v {
+		if _, err := version.Semver(); err == nil {
+			result = append(result, version)
+		}
+	}
+	sort.Sort(result)
+	return result
+}
+
+func (v Collection) Filter(filter func(Version) (bool, error)) (Collection, error) {
+	if filter == nil {
+		return v, nil
+	}
+	result := make([]Version, 0, len(v))
+	for _, version := range v {
+		ok, err := filter(version)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, version)
+		}
+	}
+	return result, nil
+}
+
+func (v Collection) Find(filter func(Version) (bool, error)) (Version, error) {
+	if filter == nil {
+		return nil, nil
+	}
+	for _, version := range v {
+		ok, err := filter(version)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return version, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,280 @@
+package module
+
+import (
+	"fmt"
+	version2 "github.com/hashicorp/go-version"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Range 版本范围，支持以下写法，多个范围可以使用 || 组合：
+//
+//	latest、*                   任意版本
+//	1.21.3、v20.11.1、go1.23rc1 完整版本号，仅匹配该版本
+//	1.21、20、1.22.x、1.x       版本前缀
+//	~1.21.3                     >=1.21.3 <1.22.0
+//	^20、^1.21.3                >=20.0.0 <21.0.0、>=1.21.3 <2.0.0
+//	>=18 <21、>=1.21,<1.23      比较运算符，空格或逗号表示同时满足
+//	1.20 - 1.22                 >=1.20.0 <1.23.0
+type Range struct {
+	sets  [][]*comparator
+	exact bool
+}
+
+type comparator struct {
+	op      string
+	version *version2.Version
+}
+
+// partialRegexp 可省略部分版本号的版本，如 1、1.21、1.22.x、go1.23rc1、v20.11.1
+var partialRegexp = regexp.MustCompile(`^(?:go|v)?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(?:-?([0-9A-Za-z][0-9A-Za-z.-]*))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// partial 解析后的版本，parts为明确指定的版本号段数
+type partial struct {
+	segments [3]int
+	parts    int
+	pre      string
+}
+
+func parsePartial(s string) (*partial, error) {
+	match := partialRegexp.FindStringSubmatch(s)
+	if match == nil {
+		return nil, fmt.Errorf("[%s] is not a valid version", s)
+	}
+	p := &partial{pre: match[4]}
+	for i := 1; i <= 3; i++ {
+		if match[i] == "" || strings.ContainsAny(match[i], "xX*") {
+			break
+		}
+		p.segments[i-1], _ = strconv.Atoi(match[i])
+		p.parts++
+	}
+	if p.pre != "" {
+		// 预发布版本视为完整版本，如 go1.23rc1
+		if p.parts < 2 {
+			return nil, fmt.Errorf("[%s] is not a valid version", s)
+		}
+		p.parts = 3
+	}
+	return p, nil
+}
+
+func (p *partial) version() *version2.Version {
+	s := fmt.Sprintf("%d.%d.%d", p.segments[0], p.segments[1], p.segments[2])
+	if p.pre != "" {
+		s += "-" + p.pre
+	}
+	v, _ := version2.NewVersion(s)
+	return v
+}
+
+// next 前缀的上限，如 1.21 对应 1.22.0，20 对应 21.0.0
+func (p *partial) next() *version2.Version {
+	segments := p.segments
+	if p.parts == 0 {
+		return nil
+	}
+	segments[p.parts-1]++
+	for i := p.parts; i < 3; i++ {
+		segments[i] = 0
+	}
+	v, _ := version2.NewVersion(fmt.Sprintf("%d.%d.%d", segments[0], segments[1], segments[2]))
+	return v
+}
+
+// ParseRange 解析版本范围，版本号可以包含 go 或 v 前缀
+func ParseRange(expr string) (*Range, error) {
+	expr = strings.TrimSpace(expr)
+	r := &Range{}
+	if expr == "" || expr == "latest" || expr == "*" {
+		r.sets = [][]*comparator{nil}
+		return r, nil
+	}
+	for _, item := range strings.Split(expr, "||") {
+		set, err := parseSet(strings.TrimSpace(item))
+		if err != nil {
+			return nil, fmt.Errorf("[%s] is not a valid version range: %w", expr, err)
+		}
+		r.sets = append(r.sets, set)
+	}
+	if len(r.sets) == 1 && len(r.sets[0]) == 1 && r.sets[0][0].op == "=" {
+		r.exact = !strings.ContainsAny(expr, "<>=~^ ")
+	}
+	return r, nil
+}
+
+func parseSet(expr string) ([]*comparator, error) {
+	if expr == "" || expr == "*" {
+		return nil, nil
+	}
+	// 1.20 - 1.22
+	if from, to, ok := strings.Cut(expr, " - "); ok {
+		lower, err := expand(">=", strings.TrimSpace(from))
+		if err != nil {
+			return nil, err
+		}
+		upper, err := expand("<=", strings.TrimSpace(to))
+		if err != nil {
+			return nil, err
+		}
+		return append(lower, upper...), nil
+	}
+	fields := strings.Fields(strings.ReplaceAll(expr, ",", " "))
+	var set []*comparator
+	for i := 0; i < len(fields); i++ {
+		match := operatorRegexp.FindStringSubmatch(fields[i])
+		op, version := match[1], match[2]
+		if op != "" && version == "" && i+1 < len(fields) {
+			// 运算符与版本号之间存在空格，如 >= 18
+			i++
+			version = fields[i]
+		}
+		comparators, err := expand(op, version)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, comparators...)
+	}
+	return set, nil
+}
+
+var operatorRegexp = regexp.MustCompile(`^(>=|<=|!=|>|<|=|~|\^)?(.*)$`)
+
+// expand 将运算符与可能省略部分版本号的版本转换为比较条件
+func expand(op, version string) ([]*comparator, error) {
+	if version == "" && op != "" {
+		// 运算符后缺少版本号，如单独的 >=、~
+		return nil, fmt.Errorf("missing version after [%s]", op)
+	}
+	if version == "" || version == "*" || version == "x" || version == "X" {
+		return nil, nil
+	}
+	p, err := parsePartial(version)
+	if err != nil {
+		return nil, err
+	}
+	lower := p.version()
+	if p.parts == 0 {
+		// 仅包含通配符，如 x、*
+		return nil, nil
+	}
+	upper := p.next()
+	switch op {
+	case "", "=":
+		if p.parts == 3 {
+			return []*comparator{{"=", lower}}, nil
+		}
+		return []*comparator{{">=", lower}, {"<", upper}}, nil
+	case "~":
+		if p.parts == 1 {
+			return []*comparator{{">=", lower}, {"<", upper}}, nil
+		}
+		minor := &partial{segments: p.segments, parts: 2}
+		return []*comparator{{">=", lower}, {"<", minor.next()}}, nil
+	case "^":
+		// 第一个非零的版本号段不允许变化
+		caret := &partial{segments: p.segments, parts: 1}
+		if p.segments[0] == 0 && p.parts > 1 {
+			caret.parts = 2
+			if p.segments[1] == 0 && p.parts > 2 {
+				caret.parts = 3
+			}
+		}
+		return []*comparator{{">=", lower}, {"<", caret.next()}}, nil
+	case ">=":
+		return []*comparator{{">=", lower}}, nil
+	case ">":
+		if p.parts < 3 {
+			return []*comparator{{">=", upper}}, nil
+		}
+		return []*comparator{{">", lower}}, nil
+	case "<":
+		return []*comparator{{"<", lower}}, nil
+	case "<=":
+		if p.parts < 3 {
+			return []*comparator{{"<", upper}}, nil
+		}
+		return []*comparator{{"<=", lower}}, nil
+	case "!=":
+		if p.parts < 3 {
+			return nil, fmt.Errorf("[%s] must be a complete version", version)
+		}
+		return []*comparator{{"!=", lower}}, nil
+	}
+	return nil, fmt.Errorf("unsupported operator [%s]", op)
+}
+
+func (c *comparator) check(v *version2.Version) bool {
+	result := v.Compare(c.version)
+	switch c.op {
+	case "=":
+		return result == 0
+	case "!=":
+		return result != 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	}
+	return false
+}
+
+// Check 判断版本是否满足范围，预发布版本仅在范围中包含相同主次修订号的预发布版本时满足
+func (r *Range) Check(v *version2.Version) bool {
+	for _, set := range r.sets {
+		if r.checkSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Range) checkSet(set []*comparator, v *version2.Version) bool {
+	if len(set) == 0 {
+		// 任意版本不限制预发布版本，由模块的过滤条件决定
+		return true
+	}
+	for _, c := range set {
+		if !c.check(v) {
+			return false
+		}
+	}
+	if v.Prerelease() == "" {
+		return true
+	}
+	for _, c := range set {
+		if c.version.Prerelease() != "" && c.version.Core().Equal(v.Core()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Exact 是否为完整版本号，完整版本号无需查找即可直接使用
+func (r *Range) Exact() bool {
+	return r.exact
+}
+
+// Select 从候选版本中选择满足范围的最新版本，latest为false时选择最早的版本，不存在时返回nil
+func (r *Range) Select(candidates []*version2.Version, latest bool) *version2.Version {
+	var matched []*version2.Version
+	for _, v := range candidates {
+		if r.Check(v) {
+			matched = append(matched, v)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	sort.Sort(version2.Collection(matched))
+	if latest {
+		return matched[len(matched)-1]
+	}
+	return matched[0]
+}
@@ -0,0 +1,82 @@
+package module
+
+import (
+	version2 "github.com/hashicorp/go-version"
+	"testing"
+)
+
+func TestRangeCheck(t *testing.T) {
+	tests := []struct {
+		expr    string
+		matched []string
+		missed  []string
+	}{
+		{"latest", []string{"1.0.0", "20.11.1"}, nil},
+		{"20", []string{"20.0.0", "20.11.1"}, []string{"19.9.0", "21.0.0"}},
+		{"1.22.x", []string{"1.22.0", "1.22.5"}, []string{"1.21.9", "1.23.0"}},
+		{"^20", []string{"20.1.0", "20.11.1"}, []string{"19.0.0", "21.0.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"~1.21.3", []string{"1.21.3", "1.21.9"}, []string{"1.21.2", "1.22.0"}},
+		{">=18 <21", []string{"18.0.0", "20.11.1"}, []string{"17.9.0", "21.0.0"}},
+		{">= 1.21, < 1.23", []string{"1.21.0", "1.22.9"}, []string{"1.20.1", "1.23.0"}},
+		{"1.20 - 1.22", []string{"1.20.0", "1.22.9"}, []string{"1.19.9", "1.23.0"}},
+		{"18 || ^22", []string{"18.20.7", "22.1.0"}, []string{"20.0.0"}},
+		{"go1.21.3", []string{"1.21.3"}, []string{"1.21.4"}},
+		{"1.23", []string{"1.23.1"}, []string{"1.23rc1"}},
+		{">=1.23rc1", []string{"1.23rc2", "1.23.0"}, []string{"1.24rc1"}},
+	}
+	for _, test := range tests {
+		r, err := ParseRange(test.expr)
+		if err != nil {
+			t.Fatalf("[%s] %s", test.expr, err)
+		}
+		for _, v := range test.matched {
+			if !r.Check(version2.Must(version2.NewVersion(v))) {
+				t.Errorf("[%s] should match %s", test.expr, v)
+			}
+		}
+		for _, v := range test.missed {
+			if r.Check(version2.Must(version2.NewVersion(v))) {
+				t.Errorf("[%s] should not match %s", test.expr, v)
+			}
+		}
+	}
+}
+
+func TestRangeExact(t *testing.T) {
+	for expr, exact := range map[string]bool{"v20.11.1": true, "go1.23rc1": true, "20": false, "=1.21.3": false, "^1.21.3": false} {
+		r, err := ParseRange(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Exact() != exact {
+			t.Errorf("[%s] exact should be %v", expr, exact)
+		}
+	}
+	if _, err := ParseRange("!=18"); err == nil {
+		t.Error("expected error for an incomplete version with !=")
+	}
+	for _, expr := range []string{">=", "<", "~", ">=18 <", "1.20 - "} {
+		if _, err := ParseRange(expr); err == nil {
+			t.Errorf("expected error for [%s] without a version", expr)
+		}
+	}
+}
+
+func TestRangeSelect(t *testing.T) {
+	var candidates []*version2.Version
+	for _, v := range []string{"18.20.7", "20.9.0", "20.11.1", "22.1.0"} {
+		candidates = append(candidates, version2.Must(version2.NewVersion(v)))
+	}
+	r, _ := ParseRange("^20")
+	if v := r.Select(candidates, true); v == nil || v.Original() != "20.11.1" {
+		t.Fatalf("unexpected latest version: %v", v)
+	}
+	if v := r.Select(candidates, false); v == nil || v.Original() != "20.9.0" {
+		t.Fatalf("unexpected earliest version: %v", v)
+	}
+	r, _ = ParseRange("^16")
+	if v := r.Select(candidates, true); v != nil {
+		t.Fatalf("unexpected version: %v", v)
+	}
+}
@@ -0,0 +1,188 @@
+package module
+
+import (
+	"fmt"
+	"jianggujin.com/lvs/internal/config"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// shimsDir 启动器目录，位于DATA_HOME中
+const shimsDir = "shims"
+
+// ShimsHome 启动器目录，shims模式下需要位于PATH的最前面
+func ShimsHome() string {
+	return filepath.Join(config.GetPath(config.KeyLvsDataHome), shimsDir)
+}
+
+// ShimMode 是否使用启动器在运行时解析版本
+func ShimMode() bool {
+	return config.GetString(config.KeyLvsVersionMode) == config.VersionModeShims
+}
+
+// VersionEnvKey 指定模块版本的环境变量，优先级高于工作空间与全局默认版本，如 LVS_GO_VERSION
+func VersionEnvKey(name string) string {
+	return config.EnvLvsPrefix + strings.ToUpper(name) + "_VERSION"
+}
+
+// ShimVersion 启动器使用的版本，优先级依次为环境变量、工作空间版本、全局默认版本，均未声明时返回空
+func (c *Command) ShimVersion() (string, string, error) {
+	key := VersionEnvKey(c.Name())
+	if version := os.Getenv(key); version != "" {
+		return version, key, nil
+	}
+	return c.DefaultVersion()
+}
+
+// Binaries 所有已安装版本中可执行文件的名称，包含通过 npm i -g 等方式安装的命令
+func (c *Command) Binaries() ([]string, error) {
+	entries, _, err := c.InstalledVersions()
+	if err != nil {
+		return nil, err
+	}
+	home := config.GetPath(c.Keys().Home)
+	var dirs []string
+	for _, entry := range entries {
+		dirs = append(dirs, c.BinDir(filepath.Join(home, entry.Name())))
+	}
+	return Executables(dirs...), nil
+}
+
+// Executables 目录中可执行文件的名称，windows中去除扩展名
+func Executables(dirs ...string) []string {
+	names := make(map[string]bool)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			// 符号链接需要获取目标文件的信息，如 npm、npx
+			info, err := os.Stat(filepath.Join(dir, entry.Name()))
+			if err != nil || info.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				ext := strings.ToLower(filepath.Ext(entry.Name()))
+				if ext == ".exe" || ext == ".cmd" || ext == ".bat" {
+					names[strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))] = true
+				}
+			} else if info.Mode()&0111 != 0 {
+				names[entry.Name()] = true
+			}
+		}
+	}
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// shimPath 启动器的文件路径
+func shimPath(tool string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(ShimsHome(), tool+".cmd")
+	}
+	return filepath.Join(ShimsHome(), tool)
+}
+
+// ShimModule 已生成的启动器所属的模块，启动器不存在或不是由LVS生成时返回空
+func ShimModule(tool string) string {
+	data, err := os.ReadFile(shimPath(tool))
+	if err != nil {
+		return ""
+	}
+	_, after, ok := strings.Cut(string(data), " shim-exec ")
+	if !ok {
+		return ""
+	}
+	if fields := strings.Fields(after); len(fields) > 1 && fields[1] == tool {
+		return fields[0]
+	}
+	return ""
+}
+
+// WriteShim 生成执行 lvs shim-exec 的启动器，已存在其他模块的同名启动器时不覆盖，返回该模块的名称
+func WriteShim(module, tool string) (string, error) {
+	if owner := ShimModule(tool); owner != "" && owner != module {
+		return owner, nil
+	}
+	lvs, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(ShimsHome(), os.ModePerm); err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "windows" {
+		content := fmt.Sprintf("@echo off\r\nrem generated by lvs, run 'lvs reshim' to regenerate\r\n\"%s\" shim-exec %s %s %%*\r\n", lvs, module, tool)
+		return "", os.WriteFile(shimPath(tool), []byte(content), 0644)
+	}
+	content := fmt.Sprintf("#!/bin/sh\n# generated by lvs, run 'lvs reshim' to regenerate\nexec \"%s\" shim-exec %s %s \"$@\"\n", lvs, module, tool)
+	return "", os.WriteFile(shimPath(tool), []byte(content), 0755)
+}
+
+// ShimConflict 与其他模块同名而未生成启动器的可执行文件
+type ShimConflict struct {
+	Tool   string
+	Module string // 已生成启动器的模块
+}
+
+// WriteShims 为模块的可执行文件生成启动器，返回已生成的启动器名称与冲突的可执行文件
+func WriteShims(module string, binaries []string) ([]string, []*ShimConflict, error) {
+	var written []string
+	var conflicts []*ShimConflict
+	for _, binary := range binaries {
+		owner, err := WriteShim(module, binary)
+		if err != nil {
+			return nil, nil, err
+		}
+		if owner != "" {
+			conflicts = append(conflicts, &ShimConflict{Tool: binary, Module: owner})
+			continue
+		}
+		written = append(written, binary)
+	}
+	return written, conflicts, nil
+}
+
+// Reshim 为模块所有已安装版本中的可执行文件生成启动器，返回启动器的名称与冲突的可执行文件
+func (c *Command) Reshim() ([]string, []*ShimConflict, error) {
+	binaries, err := c.Binaries()
+	if err != nil {
+		return nil, nil, err
+	}
+	return WriteShims(c.Name(), binaries)
+}
+
+// ShimDir 启动器使用的版本目录，未声明版本时使用软链指向的版本
+func (c *Command) ShimDir() (string, error) {
+	version, _, err := c.ShimVersion()
+	if err != nil {
+		return "", err
+	}
+	if version == "" {
+		return config.GetPath(c.Keys().Symlink), nil
+	}
+	return c.InstalledDir(version)
+}
+
+// reshimAfterInstall shims模式下安装新版本后为新增的可执行文件生成启动器
+func (c *Command) reshimAfterInstall() {
+	if !ShimMode() {
+		return
+	}
+	_, conflicts, err := c.Reshim()
+	if err != nil {
+		fmt.Printf("generate launchers error: %v, please run '%s reshim'\n", err, config.Name())
+		return
+	}
+	for _, conflict := range conflicts {
+		fmt.Printf("launcher [%s] is already provided by [%s], skipped\n", conflict.Tool, conflict.Module)
+	}
+}
@@ -0,0 +1,104 @@
+package module
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ToolVersions asdf与mise使用的版本文件
+const ToolVersions = ".tool-versions"
+
+// toolNames asdf插件名称与模块名称不一致的映射，其余插件名称即为模块名称
+var toolNames = map[string]string{
+	"golang": "go",
+	"nodejs": "node",
+}
+
+// toolName 将插件名称转换为模块名称
+func toolName(plugin string) string {
+	if name, ok := toolNames[plugin]; ok {
+		return name
+	}
+	return plugin
+}
+
+// pluginName 写入 .tool-versions 时使用的插件名称，与asdf保持一致
+func pluginName(name string) string {
+	for plugin, module := range toolNames {
+		if module == name {
+			return plugin
+		}
+	}
+	return name
+}
+
+// ToolVersionsFile 读取 .tool-versions 中指定模块的版本，存在多个版本时使用第一个，
+// system、ref:、path: 等无法由LVS管理的版本会被忽略
+func ToolVersionsFile(name string) *WorkspaceFile {
+	return &WorkspaceFile{Name: ToolVersions, Parse: func(data []byte) (string, error) {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			fields := toolFields(scanner.Text())
+			if len(fields) < 2 || toolName(fields[0]) != name {
+				continue
+			}
+			version := fields[1]
+			if version == "system" || strings.Contains(version, ":") {
+				return "", nil
+			}
+			return version, nil
+		}
+		return "", scanner.Err()
+	}}
+}
+
+// toolFields 去除注释后按空白拆分
+func toolFields(line string) []string {
+	if index := strings.Index(line, "#"); index >= 0 {
+		line = line[:index]
+	}
+	return strings.Fields(line)
+}
+
+// toolVersionRegexp 插件名称与第一个版本，之后的备用版本与注释保持不变
+var toolVersionRegexp = regexp.MustCompile(`^(\s*[^\s#]+)(?:(\s+)([^\s#]+))?`)
+
+// replaceToolVersion 仅替换行中的第一个版本，保留原有的插件名称、备用版本与注释
+func replaceToolVersion(line, version string) string {
+	match := toolVersionRegexp.FindStringSubmatchIndex(line)
+	if match[6] >= 0 {
+		return line[:match[6]] + version + line[match[7]:]
+	}
+	return line[:match[3]] + " " + version + line[match[3]:]
+}
+
+// WriteToolVersion 更新 .tool-versions 中指定模块的第一个版本，保留其他内容，不存在时追加到末尾
+func WriteToolVersion(path, name, version string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var lines []string
+	found := false
+	if content := strings.TrimRight(string(data), "\n"); content != "" {
+		for _, line := range strings.Split(content, "\n") {
+			fields := toolFields(line)
+			if len(fields) > 0 && toolName(fields[0]) == name {
+				if found {
+					// 重复声明的模块仅保留第一行
+					continue
+				}
+				line = replaceToolVersion(line, version)
+				found = true
+			}
+			lines = append(lines, line)
+		}
+	}
+	if !found {
+		lines = append(lines, pluginName(name)+" "+version)
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
@@ -0,0 +1,54 @@
+package module
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestToolVersionsFile(t *testing.T) {
+	content := []byte("# pinned tools\ngolang 1.22.3 1.21.10\nnodejs 20.11.1 # lts\njava system\n")
+	tests := map[string]string{"go": "1.22.3", "node": "20.11.1", "java": "", "python": ""}
+	for name, expected := range tests {
+		version, err := ToolVersionsFile(name).Parse(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != expected {
+			t.Errorf("[%s] expected %s, got %s", name, expected, version)
+		}
+	}
+}
+
+func TestWriteToolVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ToolVersions)
+	if err := WriteToolVersion(path, "go", "1.22.3"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "golang 1.22.3\n" {
+		t.Fatalf("unexpected content:\n%s", data)
+	}
+	if err := os.WriteFile(path, []byte("# pinned tools\ngolang 1.21.0\nnodejs 18.20.7 16.20.2 # fallback\npython\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteToolVersion(path, "go", "1.22.3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteToolVersion(path, "node", "20.11.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteToolVersion(path, "python", "3.12.2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteToolVersion(path, "java", "21"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# pinned tools\ngolang 1.22.3\nnodejs 20.11.1 16.20.2 # fallback\npython 3.12.2\njava 21\n"
+	if string(data) != expected {
+		t.Fatalf("unexpected content:\n%s", data)
+	}
+}
@@ -1,4 +1,4 @@
-package gom
+package module
 
 import (
 	"fmt"
@@ -9,10 +9,13 @@ import (
 )
 
 func init() {
-	goCmd.AddCommand(&UnAliasCommand{})
+	addCommand(func(c *Command) util.Command {
+		return &UnAliasCommand{module: c}
+	})
 }
 
 type UnAliasCommand struct {
+	module *Command
 }
 
 func (command *UnAliasCommand) Init() *cobra.Command {
@@ -30,7 +33,7 @@ func (command *UnAliasCommand) RunE(_ *cobra.Command, args []string) error {
 	}
 	for _, name := range args {
 		name = strings.ToLower(name)
-		config.Set(config.KeyGoAliasPrefix+name, "")
+		config.Set(command.module.Keys().Alias+name, "")
 		fmt.Printf("unalias: %s\n", name)
 	}
 	if err := config.SaveConfig(); err != nil {
@@ -1,4 +1,4 @@
-package gom
+package module
 
 import (
 	"fmt"
@@ -10,31 +10,35 @@ import (
 )
 
 func init() {
-	goCmd.AddCommand(&UninstallCommand{})
+	addCommand(func(c *Command) util.Command {
+		return &UninstallCommand{module: c}
+	})
 }
 
 type UninstallCommand struct {
+	module *Command
 }
 
 func (command *UninstallCommand) Init() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "uninstall",
-		Short: "Uninstall the installed version of Go",
-		RunE:  command.RunE,
+		Use:               "uninstall",
+		Short:             fmt.Sprintf("Uninstall the installed version of %s", command.module.Title()),
+		RunE:              command.RunE,
+		ValidArgsFunction: command.module.completeInstalled(true, false),
 	}
 	return cmd
 }
 
 func (command *UninstallCommand) RunE(_ *cobra.Command, versions []string) error {
-	installHome := config.GetPath(config.KeyGoHome)
+	installHome := config.GetPath(command.module.Keys().Home)
 	for i, version := range versions {
 		if i > 0 {
 			fmt.Println()
 		}
 
 		fmt.Printf("uninstall %s start\n", version)
-		version = goCmd.FixVersion(version)
-		if _, err := goCmd.Semver(version); err != nil {
+		version = command.module.FixVersion(version)
+		if _, err := command.module.Semver(version); err != nil {
 			return util.WrapErrorMsg("[%s] is not a valid version", version)
 		}
 		path := filepath.Join(installHome, version)
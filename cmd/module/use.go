@@ -0,0 +1,163 @@
+package module
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/install"
+	"jianggujin.com/lvs/internal/invoke"
+	"jianggujin.com/lvs/internal/util"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+func init() {
+	addCommand(func(c *Command) util.Command {
+		return &UseCommand{module: c}
+	})
+}
+
+type UseCommand struct {
+	module *Command
+}
+
+func (command *UseCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "use",
+		Short:             fmt.Sprintf("Activate the specified version of %s", command.module.Title()),
+		Aliases:           []string{"u"},
+		RunE:              command.RunE,
+		ValidArgsFunction: command.module.completeInstalled(false, true),
+	}
+	return cmd
+}
+
+func (command *UseCommand) RunE(_ *cobra.Command, versions []string) error {
+	if len(versions) == 0 {
+		version, _, err := command.module.DefaultVersion()
+		if err != nil {
+			return util.WrapError(err)
+		}
+		if version == "" {
+			return nil
+		}
+		versions = []string{version}
+	}
+
+	keys := command.module.Keys()
+	installHome := config.GetPath(keys.Home)
+	version, err := command.module.ResolveInstalled(versions[0])
+	if err != nil {
+		return err
+	}
+	version = command.module.FixVersion(version)
+
+	if _, err := command.module.Semver(version); err != nil {
+		return util.WrapErrorMsg("[%s] is not a valid version", version)
+	}
+	if version == command.module.Current() {
+		fmt.Printf("[%s] has been activated\n", version)
+		return nil
+	}
+	dir := filepath.Join(installHome, version)
+	if !util.Exists(command.module.Executable(dir)) {
+		return util.WrapErrorMsg("[%s] not found", version)
+	}
+	symlink := config.GetPath(keys.Symlink)
+	if err := util.ResetSymlink(symlink, dir, true); err != nil {
+		return util.WrapErrorMsg("reset symlink error").SetErr(err)
+	}
+
+	m := config.Modules[command.module.Name()]
+	var installErr error
+	// 需要安装
+	if m != nil && os.Getenv(m.SymlinkEnvKey) != symlink {
+		envKeyValues := make(map[string]string)
+		var pathValues []string
+
+		keyValues := m.EnvKeyValues
+		if keyValues != nil {
+			for k, v := range keyValues {
+				envKeyValues[k] = v
+			}
+		}
+		values := m.PathValues
+		if len(values) > 0 {
+			pathValues = append(pathValues, values...)
+		}
+		installErr = install.Install(envKeyValues, pathValues)
+	}
+
+	if runtime.GOOS != "windows" {
+		// 只修改 /opt/ 目录下的文件**（不递归）
+		// chmod +x /opt/*
+		// 递归修改所有文件**（但不影响目录的权限
+		// find /opt/ -type f -exec chmod +x {} +
+		// find /path/to/directory -type f -exec chmod +x {} \
+		// 递归修改所有文件和目录**（包括目录的执行权限）
+		// chmod -R +x /opt/
+		if _, execErr := invoke.GetInvoker().Command("chmod", "-R", "+x", command.module.BinDir(dir)+"/"); execErr != nil {
+			return util.WrapErrorMsg("failed to grant executable permissions").SetErr(execErr)
+		}
+	}
+
+	pass := false
+	checkCount := 0
+	for {
+		if version == command.module.Current() {
+			pass = true
+			break
+		}
+		checkCount++
+		if checkCount > 10 {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	if pass {
+		if installErr != nil {
+			return util.WrapErrorMsg("[%s] has been activated. but installation failed", version).SetErr(installErr)
+		} else {
+			fmt.Printf("[%s] has been activated\n", version)
+		}
+	} else {
+		if installErr != nil {
+			return util.WrapErrorMsg("unable to obtain [%s] activation status. installation failed", version).SetErr(installErr)
+		} else {
+			if runtime.GOOS == "windows" {
+				fmt.Printf("unable to obtain [%s] activation status, please try restarting the terminal\n", version)
+			} else {
+				fmt.Printf("unable to obtain [%s] activation status, please try restarting the terminal or run 'source %s'\n",
+					version, config.GetString(config.KeyShellConfigPath))
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveInstalled 将别名、latest、完整版本号或版本范围转换为本地已安装的版本
+func (c *Command) ResolveInstalled(version string) (string, error) {
+	version, err := c.AliasVersion(version)
+	if err != nil {
+		return version, err
+	}
+	r, err := ParseRange(version)
+	if err != nil {
+		return version, err
+	}
+	if r.Exact() {
+		return c.FixVersion(version), nil
+	}
+	_, installed, err := c.InstalledVersions()
+	if err != nil {
+		return version, util.WrapErrorMsg("find local installed version error").SetErr(err)
+	}
+	matched := r.Select(installed, true)
+	if matched == nil {
+		return version, fmt.Errorf("unable to find a version that matches the criteria [%s]", version)
+	}
+	return c.RawVersion(matched), nil
+}
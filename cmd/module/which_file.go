@@ -0,0 +1,48 @@
+package module
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/util"
+	"os"
+	"strings"
+)
+
+func init() {
+	addCommand(func(c *Command) util.Command {
+		return &WhichFileCommand{module: c}
+	})
+}
+
+type WhichFileCommand struct {
+	module *Command
+}
+
+func (command *WhichFileCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "which-file",
+		Short: "Display the file that declares the workspace version",
+		RunE:  command.RunE,
+	}
+	return cmd
+}
+
+func (command *WhichFileCommand) RunE(*cobra.Command, []string) error {
+	version, path, err := command.module.WorkspaceVersion()
+	if err != nil {
+		return util.WrapError(err)
+	}
+	if path == "" {
+		dir, _ := os.Getwd()
+		var names []string
+		for _, file := range command.module.WorkspaceFiles() {
+			names = append(names, file.Name)
+		}
+		fmt.Printf("no workspace version found from [%s] up to the boundary [%s], supported files: %s\n",
+			dir, config.GetString(config.KeyLvsWorkspaceBoundary), strings.Join(names, ", "))
+		return nil
+	}
+	fmt.Printf("%s: %s\n", path, version)
+	return nil
+}
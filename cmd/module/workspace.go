@@ -0,0 +1,147 @@
+package module
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/mitchellh/go-homedir"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/util"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WorkspaceFile 工作空间中声明版本的文件
+type WorkspaceFile struct {
+	Name  string                            // 文件名
+	Parse func(data []byte) (string, error) // 解析出版本号或版本范围，未声明版本时返回空
+}
+
+// WorkspaceProvider 可选接口，模块支持的其他工作空间版本文件，按照优先级排列
+type WorkspaceProvider interface {
+	WorkspaceFiles() []*WorkspaceFile
+}
+
+// DefaultWorkspaceFiles 所有模块通用的工作空间版本文件，<模块名称>.lvsrc 优先于 .tool-versions
+func DefaultWorkspaceFiles(name string) []*WorkspaceFile {
+	return []*WorkspaceFile{
+		{Name: name + config.KeyWorkspaceSuffix, Parse: ParseVersionFile},
+		ToolVersionsFile(name),
+	}
+}
+
+// WorkspaceFiles 按照优先级排列的工作空间版本文件，模块支持的其他文件优先级低于通用文件
+func (c *Command) WorkspaceFiles() []*WorkspaceFile {
+	files := DefaultWorkspaceFiles(c.Name())
+	if p, ok := c.Provider.(WorkspaceProvider); ok {
+		files = append(files, p.WorkspaceFiles()...)
+	}
+	return files
+}
+
+// WorkspaceVersion 查找当前目录生效的工作空间版本
+func (c *Command) WorkspaceVersion() (string, string, error) {
+	return FindWorkspaceVersion(c.WorkspaceFiles())
+}
+
+// DefaultVersion 未指定版本时使用的版本，工作空间中声明的版本优先于全局默认版本，
+// 返回版本与来源，来源为声明版本的文件路径或global
+func (c *Command) DefaultVersion() (string, string, error) {
+	version, path, err := c.WorkspaceVersion()
+	if err != nil || version != "" {
+		return version, path, err
+	}
+	if version = config.GetString(c.Keys().Global); version != "" {
+		return version, "global", nil
+	}
+	return "", "", nil
+}
+
+// FindWorkspaceVersion 从当前目录开始向上查找声明的版本，同一目录中按照文件优先级查找，
+// 返回版本与声明版本的文件路径，未声明时均返回空
+func FindWorkspaceVersion(files []*WorkspaceFile) (string, string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", "", err
+	}
+	for _, dir = range WorkspaceDirs(dir) {
+		for _, file := range files {
+			path := filepath.Join(dir, file.Name)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return "", path, err
+			}
+			version, err := file.Parse(data)
+			if err != nil {
+				return "", path, fmt.Errorf("parse [%s] error: %w", path, err)
+			}
+			if version != "" {
+				return version, path, nil
+			}
+		}
+	}
+	return "", "", nil
+}
+
+// vcsDirs 版本控制根目录的标识
+var vcsDirs = []string{".git", ".hg", ".svn"}
+
+// WorkspaceDirs 从dir开始依次列出上级目录，直到WORKSPACE_BOUNDARY配置的边界目录（包含边界目录）或文件系统根目录
+func WorkspaceDirs(dir string) []string {
+	boundaries := config.GetList(config.KeyLvsWorkspaceBoundary)
+	home, _ := homedir.Dir()
+	var dirs []string
+	for {
+		dirs = append(dirs, dir)
+		if isBoundary(dir, home, boundaries) {
+			return dirs
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dirs
+		}
+		dir = parent
+	}
+}
+
+func isBoundary(dir, home string, boundaries []string) bool {
+	for _, boundary := range boundaries {
+		switch boundary {
+		case config.BoundaryRoot:
+		case config.BoundaryHome:
+			if home != "" && dir == filepath.Clean(home) {
+				return true
+			}
+		case config.BoundaryVcs:
+			for _, vcs := range vcsDirs {
+				if util.Exists(filepath.Join(dir, vcs)) {
+					return true
+				}
+			}
+		default:
+			if path, err := homedir.Expand(boundary); err == nil && dir == filepath.Clean(path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParseVersionFile 读取第一个非空且不是注释的行，适用于只包含版本号的文件
+func ParseVersionFile(data []byte) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			return line, nil
+		}
+	}
+	return "", scanner.Err()
+}
@@ -0,0 +1,66 @@
+#!/bin/bash
+
+# 重新生成内置的node.js发布密钥环，密钥来源于 https://github.com/nodejs/release-keys
+# 指纹列表参见 https://github.com/nodejs/node#release-keys
+
+set -e
+
+KEYRING=$(cd "$(dirname "$0")" && pwd)/nodejs.asc
+KEYS_URL="https://raw.githubusercontent.com/nodejs/release-keys/HEAD/keys"
+
+FINGERPRINTS=(
+  # 当前发布者
+  5BE8A3F6C8A5C01D106C0AD820B1A390B168D356 # Antoine du Hamel
+  DD792F5973C6DE52C432CBDAC77ABFA00DDBF2B7 # Juan José Arboleda
+  CC68F5A3106FF448322E48ED27F5E38D5B0A215F # Marco Ippolito
+  8FCCA13FEF1D0C2E91008E09770F7A9A5AE15600 # Michaël Zasso
+  890C08DB8579162FEE0DF9DB8BEAB4DFCF555EF4 # Rafael Gonzaga
+  C82FA3AE1CBEDC6BE46B9360C43CEC45C17AB93C # Richard Lau
+  108F52B48DB57BB0CC439B2997B01419BD92F80A # Ruy Adorno
+  A363A499291CBBC940DD62E41F10027AF002F8B0 # Ulises Gascón
+  # 历史版本发布者
+  C0D6248439F1D5604AAFFB4021D900FFDB233756 # Antoine du Hamel
+  4ED778F539E3634C779C87C6D7062848A1AB005C # Beth Griggs
+  141F07595B7B3FFE74309A937405533BE57C7D57 # Bryan English
+  9554F04D7259F04124DE6B476D5A82AC7E37093B # Chris Dickinson
+  94AE36675C464D64BAFA68DD7434390BDBE9B9C5 # Colin Ihrig
+  1C050899334244A8AF75E53792EF661D867B9DFA # Danielle Adams
+  74F12602B6F1C4E913FAA37AD3A89613643B6201 # Danielle Adams
+  B9AE9905FFD7803F25714661B63B535A4C206CA9 # Evan Lucas
+  77984A986EBC2AA786BC0F66B01FBB92821C587A # Gibson Fahnestock
+  93C7E9E91B49E432C2F75674B0A78B0A6C481CF6 # Isaac Z. Schlueter
+  56730D5401028683275BD23C23EFEFE93C4CFFFE # Italo A. Casas
+  71DCFD284A79C3B38668286BC97EC7A07EDE3FC1 # James M Snell
+  FD3A5288F042B6850C66B31F09FE44734EB7990E # Jeremiah Senkpiel
+  61FC681DFB92A079F1685E77973F295594EC4689 # Juan José Arboleda
+  114F43EE0176B71C7BC219DD50A3051F888C628D # Julien Gilli
+  C4F0DFFF4E8C1A8236409D08E73BC641CC11F4C8 # Myles Borins
+  DD8F2338BAE7501E3DD5AC78C273792F7D83545D # Rod Vagg
+  A48C2BEE680E841632CD4E44F07496B3EB3C1762 # Ruben Bridgewater
+  B9E2F5981AA6E0CD28160D9FF13993A75599653C # Shelley Vohr
+  7937DFD2AB06298B2293C3187D33FF9D0246406D # Timothy J Fontaine
+)
+
+command -v gpg >/dev/null || { echo "gpg is required to verify the key fingerprints" >&2; exit 1; }
+
+TEMP=$(mktemp)
+KEY=$(mktemp)
+GNUPG=$(mktemp -d)
+trap 'rm -rf "$TEMP" "$KEY" "$GNUPG"' EXIT
+
+echo "# node.js release keys, generated by update.sh" > "$TEMP"
+for fingerprint in "${FINGERPRINTS[@]}"; do
+  echo "[ FETCH $fingerprint ]"
+  curl -fsSL "$KEYS_URL/$fingerprint.asc" -o "$KEY"
+  # 文件中只能包含一个主密钥，且指纹与列表一致，防止写入被篡改的密钥
+  actual=$(gpg --homedir "$GNUPG" --batch --with-colons --import-options show-only --import "$KEY" 2>/dev/null |
+    awk -F: '$1 == "pub" { pub = 1; next } pub && $1 == "fpr" { print $10; pub = 0 }')
+  if [ "$actual" != "$fingerprint" ]; then
+    echo "[ FINGERPRINT MISMATCH $fingerprint, got: ${actual:-none} ]" >&2
+    exit 1
+  fi
+  cat "$KEY" >> "$TEMP"
+  echo >> "$TEMP"
+done
+mv "$TEMP" "$KEYRING"
+echo "[ KEYRING UPDATED $KEYRING ]"
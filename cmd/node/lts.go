@@ -0,0 +1,171 @@
+//go:build (windows && (amd64 || 386 || arm64)) || (linux && (amd64 || arm || armv7l || arm64 || ppc64le || s390x)) || (darwin && (amd64 || arm64))
+
+package node
+
+import (
+	"fmt"
+	version2 "github.com/hashicorp/go-version"
+	"github.com/spf13/cast"
+	"jianggujin.com/lvs/cmd/module"
+	"jianggujin.com/lvs/internal/config"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ltsPrefix 与nvm兼容的LTS别名前缀，支持 lts/*、lts/<codename>、lts/-N
+const ltsPrefix = "lts/"
+
+// ltsLabel 安装信息中记录LTS代号的名称
+const ltsLabel = "lts"
+
+// #define NODE_VERSION_LTS_CODENAME "Iron"
+var ltsCodenameRegexp = regexp.MustCompile(`#define\s+NODE_VERSION_LTS_CODENAME\s+"([^"]+)"`)
+
+// ltsLine LTS版本线，同一代号的版本具有相同的主版本号
+type ltsLine struct {
+	codename string
+	major    int
+	first    *version2.Version // 该版本线中最早的LTS版本
+}
+
+// BuiltinAlias 将LTS别名转换为对应版本线的版本范围，如 lts/iron 转换为 >=20.9.0 <21.0.0，
+// 版本范围同时适用于远程版本与已安装的版本，远程索引不可用时仅根据已安装的版本解析
+func (p *Provider) BuiltinAlias(alias string) (string, bool, error) {
+	selector, ok := strings.CutPrefix(strings.ToLower(alias), ltsPrefix)
+	if !ok {
+		return alias, false, nil
+	}
+	versions, err := p.fetchVersions()
+	if err != nil {
+		// 离线且没有缓存的索引时，根据已安装版本记录的LTS代号解析
+		if versions = p.installedLtsVersions(); len(versions) == 0 {
+			return alias, true, err
+		}
+	}
+	line, err := selectLtsLine(ltsLines(versions), selector)
+	if err != nil {
+		return alias, true, err
+	}
+	return fmt.Sprintf(">=%s <%d.0.0", line.first.String(), line.major+1), true, nil
+}
+
+// InstallLabels 记录版本的LTS代号，使用已获取或缓存的索引，不访问网络
+func (p *Provider) InstallLabels(version, dir string) map[string]string {
+	versions := p.versions
+	if versions == nil {
+		offline := config.Offline
+		config.Offline = true
+		versions, _ = p.fetchVersions()
+		config.Offline = offline
+	}
+	for _, item := range versions {
+		if item.Version == version {
+			codename := cast.ToString(item.Lts)
+			if codename == "" || codename == "false" {
+				return nil
+			}
+			return map[string]string{ltsLabel: codename}
+		}
+	}
+	// 索引不可用时从头文件中读取
+	if codename := headerLtsCodename(dir); codename != "" {
+		return map[string]string{ltsLabel: codename}
+	}
+	return nil
+}
+
+// headerLtsCodename 从安装目录的头文件中读取LTS代号，Windows的归档文件中不包含头文件
+func headerLtsCodename(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "include", "node", "node_version.h"))
+	if err != nil {
+		return ""
+	}
+	if match := ltsCodenameRegexp.FindSubmatch(data); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
+
+// installedLtsVersions 已安装的LTS版本，LTS代号来自安装时记录的信息，未记录时从头文件中读取
+func (p *Provider) installedLtsVersions() []*Version {
+	home := config.GetPath(p.Keys().Home)
+	entries, err := os.ReadDir(home)
+	if err != nil {
+		return nil
+	}
+	var versions []*Version
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(home, entry.Name())
+		var codename string
+		if metadata := module.ReadMetadata(dir); metadata != nil {
+			codename = metadata.Labels[ltsLabel]
+		} else {
+			codename = headerLtsCodename(dir)
+		}
+		if codename != "" {
+			versions = append(versions, &Version{Version: entry.Name(), Lts: codename})
+		}
+	}
+	return versions
+}
+
+// ltsLines 按照主版本号倒序排列的LTS版本线
+func ltsLines(versions []*Version) []*ltsLine {
+	var lines []*ltsLine
+	m := make(map[string]*ltsLine)
+	for _, version := range versions {
+		codename := strings.ToLower(cast.ToString(version.Lts))
+		if codename == "" || codename == "false" {
+			continue
+		}
+		semver, err := version.Semver()
+		if err != nil {
+			continue
+		}
+		line, ok := m[codename]
+		if !ok {
+			line = &ltsLine{codename: codename, major: semver.Segments()[0], first: semver}
+			m[codename] = line
+			lines = append(lines, line)
+		} else if semver.LessThan(line.first) {
+			line.first = semver
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].major > lines[j].major
+	})
+	return lines
+}
+
+// selectLtsLine 选择版本线，* 为最新的版本线，-N 为最新版本线之前的第N个版本线
+func selectLtsLine(lines []*ltsLine, selector string) (*ltsLine, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no LTS versions found")
+	}
+	if selector == "*" {
+		return lines[0], nil
+	}
+	if strings.HasPrefix(selector, "-") {
+		n, err := strconv.Atoi(selector[1:])
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("[%s%s] is not a valid LTS alias", ltsPrefix, selector)
+		}
+		if n >= len(lines) {
+			return nil, fmt.Errorf("[%s%s] exceeds the %d known LTS lines", ltsPrefix, selector, len(lines))
+		}
+		return lines[n], nil
+	}
+	for _, line := range lines {
+		if line.codename == selector {
+			return line, nil
+		}
+	}
+	return nil, fmt.Errorf("LTS codename [%s] not found", selector)
+}
@@ -0,0 +1,53 @@
+//go:build (windows && (amd64 || 386 || arm64)) || (linux && (amd64 || arm || armv7l || arm64 || ppc64le || s390x)) || (darwin && (amd64 || arm64))
+
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelectLtsLine(t *testing.T) {
+	versions := []*Version{
+		{Version: "v22.1.0", Lts: false},
+		{Version: "v20.11.1", Lts: "Iron"},
+		{Version: "v20.9.0", Lts: "Iron"},
+		{Version: "v20.8.0", Lts: false},
+		{Version: "v18.19.0", Lts: "Hydrogen"},
+		{Version: "v18.12.0", Lts: "Hydrogen"},
+	}
+	lines := ltsLines(versions)
+	tests := map[string]string{"*": "20.9.0", "iron": "20.9.0", "-1": "18.12.0", "hydrogen": "18.12.0"}
+	for selector, first := range tests {
+		line, err := selectLtsLine(lines, selector)
+		if err != nil {
+			t.Fatalf("[%s] %s", selector, err)
+		}
+		if line.first.String() != first {
+			t.Errorf("[%s] first version should be %s, got %s", selector, first, line.first)
+		}
+	}
+	for _, selector := range []string{"-2", "gallium", "-x"} {
+		if _, err := selectLtsLine(lines, selector); err == nil {
+			t.Errorf("[%s] expected error", selector)
+		}
+	}
+}
+
+func TestHeaderLtsCodename(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "include", "node"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	header := "#define NODE_VERSION_IS_LTS 1\n#define NODE_VERSION_LTS_CODENAME \"Iron\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "include", "node", "node_version.h"), []byte(header), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if codename := headerLtsCodename(dir); codename != "Iron" {
+		t.Errorf("codename should be Iron, got %s", codename)
+	}
+	if codename := headerLtsCodename(t.TempDir()); codename != "" {
+		t.Errorf("codename should be empty, got %s", codename)
+	}
+}
@@ -0,0 +1,178 @@
+//go:build (windows && (amd64 || 386 || arm64)) || (linux && (amd64 || arm || armv7l || arm64 || ppc64le || s390x)) || (darwin && (amd64 || arm64))
+
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cast"
+	"jianggujin.com/lvs/cmd/module"
+	"jianggujin.com/lvs/internal/config"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// node-v20.11.1-linux-x64.tar.gz、node-v20.11.1-win-x64.zip
+var mirrorArchiveRegexp = regexp.MustCompile(`^node-(v\d+\.\d+\.\d+)-([a-z0-9]+-[a-z0-9]+)\.(tar\.gz|tar\.xz|zip|7z)$`)
+
+func (p *Provider) ParseMirrorArchive(name string) *module.MirrorArchive {
+	match := mirrorArchiveRegexp.FindStringSubmatch(name)
+	if match == nil {
+		return nil
+	}
+	return &module.MirrorArchive{
+		Version:  match[1],
+		Platform: match[2],
+		Ext:      match[3],
+		Name:     name,
+	}
+}
+
+// v20.11.1/SHASUMS256.txt、v20.11.1/SHASUMS256.txt.asc
+var mirrorIndexRegexp = regexp.MustCompile(`^v\d+\.\d+\.\d+/SHASUMS256\.txt(?:\.asc|\.sig)?$`)
+
+func (p *Provider) MirrorIndex(rel string) bool {
+	return rel == "index.json" || mirrorIndexRegexp.MatchString(rel)
+}
+
+func (p *Provider) MirrorPath(archive *module.MirrorArchive) string {
+	return path.Join(archive.Version, archive.Name)
+}
+
+// indexFile 归档文件在index.json中files字段的标识
+func indexFile(archive *module.MirrorArchive) string {
+	switch {
+	case strings.HasPrefix(archive.Platform, "win-"):
+		return archive.Platform + "-" + archive.Ext
+	case strings.HasPrefix(archive.Platform, "darwin-"):
+		return "osx-" + strings.TrimPrefix(archive.Platform, "darwin-") + "-tar"
+	}
+	return archive.Platform
+}
+
+// parseIndexFile 将index.json中files字段的标识转换为平台标识与扩展名，仅支持可以安装的归档文件
+func parseIndexFile(file string) (string, string) {
+	switch {
+	case strings.HasPrefix(file, "win-") && strings.HasSuffix(file, "-zip"):
+		return strings.TrimSuffix(file, "-zip"), "zip"
+	case strings.HasPrefix(file, "osx-") && strings.HasSuffix(file, "-tar"):
+		return "darwin-" + strings.TrimSuffix(strings.TrimPrefix(file, "osx-"), "-tar"), "tar.xz"
+	case strings.HasPrefix(file, "linux-") && strings.Count(file, "-") == 1:
+		return file, "tar.gz"
+	}
+	return "", ""
+}
+
+// MirrorIndexes 生成index.json并提供各版本目录下上游发布的SHASUMS256.txt及其签名，
+// SHASUMS256.txt只能来自上游以保证签名有效，仅使用已获取或缓存的上游文件，不访问网络，
+// 没有缓存的版本不提供SHASUMS256.txt
+func (p *Provider) MirrorIndexes(archives []*module.MirrorArchive) (map[string][]byte, error) {
+	offline := config.Offline
+	config.Offline = true
+	defer func() {
+		config.Offline = offline
+	}()
+	upstream := make(map[string]*Version)
+	// 使用上游索引的缓存获取LTS代号等信息，没有缓存时LTS为false
+	if list, err := p.fetchVersions(); err == nil {
+		for _, version := range list {
+			upstream[version.Version] = version
+		}
+	}
+	versions := make(map[string]*Version)
+	sorted := make([]*module.MirrorArchive, len(archives))
+	copy(sorted, archives)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	for _, archive := range sorted {
+		version, ok := versions[archive.Version]
+		if !ok {
+			version = &Version{Version: archive.Version, Lts: false}
+			if item, ok := upstream[archive.Version]; ok {
+				copied := *item
+				copied.Files = nil
+				version = &copied
+			}
+			versions[archive.Version] = version
+		}
+		if _, ok := upstream[archive.Version]; !ok {
+			// 没有上游信息时使用最新的归档文件修改时间作为发布日期
+			if date := archive.ModTime.Format("2006-01-02"); date > version.Date {
+				version.Date = date
+			}
+		}
+		file := indexFile(archive)
+		exists := false
+		for _, item := range version.Files {
+			exists = exists || item == file
+		}
+		if !exists {
+			version.Files = append(version.Files, file)
+		}
+	}
+
+	var list module.Collection
+	for _, version := range versions {
+		list = append(list, version)
+	}
+	data, err := json.Marshal(list.Sort())
+	if err != nil {
+		return nil, err
+	}
+	indexes := map[string][]byte{"index.json": data}
+	for version := range versions {
+		// 安装或同步时已校验的上游文件缓存在DATA_HOME中
+		sums, err := p.fetchChecksums(version)
+		if err != nil || sums.Signature == nil {
+			continue
+		}
+		indexes[path.Join(version, "SHASUMS256.txt")] = sums.Data
+		indexes[path.Join(version, sums.SignatureName)] = sums.Signature
+	}
+	return indexes, nil
+}
+
+func (p *Provider) MirrorPlatform(goos, goarch string) string {
+	plat, _ := platform(goos, goarch)
+	return plat
+}
+
+func (p *Provider) UpstreamArchives() ([]*module.MirrorArchive, error) {
+	versions, err := p.fetchVersions()
+	if err != nil {
+		return nil, err
+	}
+	var archives []*module.MirrorArchive
+	for _, version := range versions {
+		for _, file := range version.Files {
+			plat, ext := parseIndexFile(file)
+			if plat == "" {
+				continue
+			}
+			name := fmt.Sprintf("node-%s-%s.%s", version.Version, plat, ext)
+			archives = append(archives, &module.MirrorArchive{
+				Version:  version.Version,
+				Platform: plat,
+				Ext:      ext,
+				Name:     name,
+				Lts:      cast.ToString(version.Lts) != "false",
+			})
+		}
+	}
+	return archives, nil
+}
+
+func (p *Provider) UpstreamChecksum(archive *module.MirrorArchive) (string, error) {
+	sums, err := p.fetchChecksums(archive.Version)
+	if err != nil {
+		return "", err
+	}
+	checksum := sums.files[archive.Name]
+	if checksum == "" {
+		return "", fmt.Errorf("checksum of [%s] not found", archive.Name)
+	}
+	return checksum, nil
+}
@@ -3,62 +3,75 @@
 package node
 
 import (
+	_ "embed"
 	"encoding/json"
 	"fmt"
 	version2 "github.com/hashicorp/go-version"
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"io"
+	"jianggujin.com/lvs/cmd/module"
 	"jianggujin.com/lvs/internal/config"
 	"jianggujin.com/lvs/internal/invoke"
 	"jianggujin.com/lvs/internal/util"
 	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
 	"runtime"
-	"sort"
 	"strings"
 	"time"
 )
 
-type Command struct {
-	command *cobra.Command
+//go:embed keyring/nodejs.asc
+var bundledKeyring []byte
+
+type Provider struct {
+	Lts           bool
+	Security      bool
+	SkipSignature bool
+	versions      []*Version
+	checksums     map[string]*checksums
 }
 
-var nodeCmd = &Command{
-	command: &cobra.Command{
-		Use:   "node",
-		Short: "Node.js version management",
-	},
+// checksums 版本目录中的 SHASUMS256.txt 及其签名，校验通过后缓存在本地
+type checksums struct {
+	Data          []byte `json:"data"`
+	Signature     []byte `json:"signature,omitempty"`
+	SignatureName string `json:"signatureName,omitempty"`
+	files         map[string]string
 }
 
 func Init(rootCmd *cobra.Command) {
-	rootCmd.AddCommand(nodeCmd.command)
+	module.Init(rootCmd, &Provider{})
 }
 
-func (c *Command) AddCommand(commands ...util.Command) {
-	for _, cmd := range commands {
-		util.AddCommand(c.command, cmd)
-	}
+func (p *Provider) Name() string {
+	return config.ModuleNode
 }
 
-func (c *Command) Version() string {
-	str, err := invoke.GetInvoker().Command("node", "-v")
-	if err != nil {
-		return ""
-	}
-	return strings.TrimSpace(string(str))
+func (p *Provider) Title() string {
+	return "node.js"
 }
 
-func (c *Command) NewHttpClient(opts ...util.HttpClientOption) *http.Client {
-	ops := append([]util.HttpClientOption{util.WithProxyStr(config.GetStringWithDefault(config.KeyNodeProxy, config.GetString(config.KeyLvsProxy)))}, opts...)
-	return util.NewHttpClient(ops...)
+func (p *Provider) Keys() *module.Keys {
+	return &module.Keys{
+		Home:    config.KeyNodeHome,
+		Symlink: config.KeyNodeSymlink,
+		Mirror:  config.KeyNodeMirror,
+		Proxy:   config.KeyNodeProxy,
+		Alias:   config.KeyNodeAliasPrefix,
+		Global:  config.KeyNodeGlobal,
+	}
 }
 
-func (c *Command) Get(url string, opts ...util.HttpClientOption) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func (p *Provider) Current() string {
+	str, err := invoke.GetInvoker().Command("node", "-v")
 	if err != nil {
-		return nil, err
+		return ""
 	}
-	req.Header.Set("User-Agent", fmt.Sprintf("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36 LVS/%s", config.BuildVersion))
-	return c.NewHttpClient(opts...).Do(req)
+	return strings.TrimSpace(string(str))
 }
 
 type Version struct {
@@ -76,6 +89,10 @@ type Version struct {
 	semver   *version2.Version
 }
 
+func (v *Version) Raw() string {
+	return v.Version
+}
+
 func (v *Version) Semver() (*version2.Version, error) {
 	if v.semver == nil {
 		semver, err := version2.NewVersion(v.Version)
@@ -87,205 +104,249 @@ func (v *Version) Semver() (*version2.Version, error) {
 	return v.semver, nil
 }
 
-func (c *Command) Semver(version string) (*version2.Version, error) {
+func (p *Provider) Semver(version string) (*version2.Version, error) {
 	return version2.NewVersion(version)
 }
 
-func (c *Command) FixVersion(version string) string {
-	if version[0] != 'v' {
+func (p *Provider) FixVersion(version string) string {
+	if version != "" && version[0] != 'v' {
 		version = "v" + version
 	}
 	return version
 }
 
-func (c *Command) RawVersion(version *version2.Version) string {
+func (p *Provider) RawVersion(version *version2.Version) string {
 	return version.Original()
 }
 
-type Collection []*Version
+// fetchVersions 获取index.json中所有平台的版本
+func (p *Provider) fetchVersions() ([]*Version, error) {
+	if p.versions != nil {
+		return p.versions, nil
+	}
+	var versions []*Version
+	err := p.failover().FetchIndex(config.ModuleNode, "index.json", &versions, func(data []byte, _ string) error {
+		return json.Unmarshal(data, &versions)
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.versions = versions
+	return versions, nil
+}
+
+func (p *Provider) RemoteVersions() (module.Collection, error) {
+	plat, ext := platform(runtime.GOOS, runtime.GOARCH)
+	if plat == "" {
+		return nil, fmt.Errorf("unsupported architecture: %s", runtime.GOARCH)
+	}
+	fileName := indexFile(&module.MirrorArchive{Platform: plat, Ext: ext})
 
-func (v Collection) Len() int {
-	return len(v)
+	versions, err := p.fetchVersions()
+	if err != nil {
+		return nil, err
+	}
+	var list module.Collection
+	for _, nv := range versions {
+		for _, fName := range nv.Files {
+			if fName == fileName {
+				list = append(list, nv)
+				break
+			}
+		}
+	}
+	return list.Sort(), nil
 }
 
-func (v Collection) Less(i, j int) bool {
-	return v[i].semver.GreaterThan(v[j].semver)
+func (p *Provider) Columns() []string {
+	return []string{"Npm", "Lts", "Security", "Date"}
 }
 
-func (v Collection) Swap(i, j int) {
-	v[i], v[j] = v[j], v[i]
+func (p *Provider) Row(version module.Version) []string {
+	ver := version.(*Version)
+	return []string{ver.Npm, cast.ToString(ver.Lts), cast.ToString(ver.Security), ver.Date}
 }
 
-func (v Collection) Filter(filter func(*Version) (bool, error)) (Collection, error) {
-	if filter == nil {
-		return v, nil
-	}
-	result := make([]*Version, 0, len(v))
-	for _, version := range v {
-		ok, err := filter(version)
-		if err != nil {
-			return nil, err
-		}
-		if ok {
-			result = append(result, version)
-		}
-	}
-	return result, nil
+func (p *Provider) InstallFlags(flags *pflag.FlagSet) {
+	flags.BoolVarP(&p.Lts, "lts", "L", true, "long-term support version")
+	flags.BoolVarP(&p.Security, "security", "s", false, "security fix version")
+	flags.BoolVar(&p.SkipSignature, "skip-signature", false, "skip OpenPGP signature verification of SHASUMS256.txt, NOT recommended")
 }
 
-func (v Collection) Find(filter func(*Version) (bool, error)) (*Version, error) {
-	if filter == nil {
-		return nil, nil
+func (p *Provider) InstallFilter(version module.Version) (bool, error) {
+	ver := version.(*Version)
+	if p.Lts && "false" == cast.ToString(ver.Lts) {
+		return false, nil
 	}
-	for _, version := range v {
-		ok, err := filter(version)
-		if err != nil {
-			return nil, err
-		}
-		if ok {
-			return version, nil
-		}
+	if p.Security && !ver.Security {
+		return false, nil
 	}
-	return nil, nil
+	return true, nil
 }
 
-func (c *Command) ListVersions(filter func(*Version) (bool, error)) ([]*Version, error) {
-	var fileName string
-	switch runtime.GOOS {
+// platform GOOS与GOARCH对应的归档文件平台标识与扩展名，不支持时返回空
+func platform(goos, goarch string) (string, string) {
+	switch goos {
 	case "windows":
-		switch runtime.GOARCH {
+		// https://nodejs.org/dist/v22.14.0/node-v22.14.0-win-x64.zip
+		switch goarch {
 		case "amd64":
-			fileName = "win-x64-zip"
+			return "win-x64", "zip"
 		case "386":
-			fileName = "win-x86-zip"
+			return "win-x86", "zip"
 		case "arm64":
-			fileName = "win-arm64-zip"
+			return "win-arm64", "zip"
 		}
 	case "darwin":
-		switch runtime.GOARCH {
+		// https://nodejs.org/dist/v22.14.0/node-v22.14.0-darwin-arm64.tar.xz
+		switch goarch {
 		case "amd64":
-			fileName = "osx-x64-tar"
+			return "darwin-x64", "tar.xz"
 		case "arm64":
-			fileName = "osx-arm64-tar"
+			return "darwin-arm64", "tar.xz"
 		}
 	case "linux":
-		switch runtime.GOARCH {
+		// https://nodejs.org/dist/v22.14.0/node-v22.14.0-linux-x64.tar.gz
+		// linux gz > xz
+		switch goarch {
 		case "amd64":
-			fileName = "linux-x64"
+			return "linux-x64", "tar.gz"
 		case "arm", "armv7l":
-			fileName = "linux-armv7l"
-		case "arm64":
-			fileName = "linux-arm64"
-		case "ppc64le":
-			fileName = "linux-ppc64le"
-		case "s390x":
-			fileName = "linux-s390x"
+			return "linux-armv7l", "tar.gz"
+		case "arm64", "ppc64le", "s390x":
+			return "linux-" + goarch, "tar.gz"
 		}
 	}
-	if fileName == "" {
+	return "", ""
+}
+
+func (p *Provider) ConvertDownload(version string) (*module.Download, error) {
+	plat, ext := platform(runtime.GOOS, runtime.GOARCH)
+	if plat == "" {
 		return nil, fmt.Errorf("unsupported architecture: %s", runtime.GOARCH)
 	}
+	return &module.Download{
+		Version:  version,
+		BaseName: fmt.Sprintf("node-%s-%s", version, plat),
+		Ext:      ext,
+	}, nil
+}
 
-	fetchUrl := config.GetString(config.KeyNodeMirror) + "index.json"
-	resp, err := c.Get(fetchUrl, util.WithTimeout(30*time.Second))
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+var archiveRegexp = regexp.MustCompile(`^node-(v\d+\.\d+\.\d+)-`)
+
+func (p *Provider) ArchiveVersion(name string) string {
+	// node-v20.11.1-linux-x64.tar.gz
+	match := archiveRegexp.FindStringSubmatch(name)
+	if match == nil {
+		return ""
 	}
-	var versions []*Version
-	data, err := io.ReadAll(resp.Body)
+	return match[1]
+}
+
+func (p *Provider) DownloadPath(download *module.Download) string {
+	return fmt.Sprintf("%s/%s.%s", download.Version, download.BaseName, download.Ext)
+}
+
+func (p *Provider) Checksum(download *module.Download) (string, error) {
+	sums, err := p.fetchChecksums(download.Version)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	if err = json.Unmarshal(data, &versions); err != nil {
-		return nil, err
+	name := fmt.Sprintf("%s.%s", download.BaseName, download.Ext)
+	checksum := sums.files[name]
+	if checksum == "" {
+		return "", fmt.Errorf("checksum of [%s] not found", name)
 	}
-	defaultFilter := func(nv *Version) (bool, error) {
-		if len(nv.Files) == 0 {
-			return false, nil
+	return checksum, nil
+}
+
+// fetchChecksums 获取并校验版本目录中的 SHASUMS256.txt
+func (p *Provider) fetchChecksums(version string) (*checksums, error) {
+	if sums, ok := p.checksums[version]; ok {
+		return sums, nil
+	}
+	// 每个版本目录中都发布了 SHASUMS256.txt 及其签名，签名需要从同一个镜像获取
+	rel := version + "/SHASUMS256.txt"
+	sums := &checksums{}
+	if p.SkipSignature {
+		// 未经校验的内容不写入缓存
+		data, mirror, err := p.failover().Fetch(rel, util.WithTimeout(30*time.Second))
+		if err != nil {
+			return nil, err
 		}
-		has := false
-		for _, fName := range nv.Files {
-			if fName == fileName {
-				has = true
-				break
+		fmt.Fprintf(os.Stderr, "\nWARNING: OpenPGP signature verification of [%s] SHASUMS256.txt is SKIPPED, the archive is only as trustworthy as the mirror [%s]\n",
+			version, mirror)
+		sums.Data = data
+	} else {
+		err := p.failover().FetchIndex(fmt.Sprintf("%s-%s-SHASUMS256", config.ModuleNode, version), rel, sums, func(data []byte, mirror string) error {
+			sums.Data = data
+			if err := p.verifySignature(fmt.Sprintf("%s%s/", mirror, version), sums); err != nil {
+				return fmt.Errorf("signature verification of [%s] SHASUMS256.txt failed: %w", version, err)
 			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
-		if !has {
-			return false, nil
-		}
-		if _, err = nv.Semver(); err != nil {
-			return false, nil
+	}
+	sums.files = util.ParseChecksums(sums.Data)
+	if p.checksums == nil {
+		p.checksums = make(map[string]*checksums)
+	}
+	p.checksums[version] = sums
+	return sums, nil
+}
+
+// verifySignature 使用发布密钥环校验 SHASUMS256.txt.asc 或 SHASUMS256.txt.sig
+func (p *Provider) verifySignature(baseUrl string, sums *checksums) error {
+	keyringData := bundledKeyring
+	if path := config.GetPath(config.KeyNodeKeyring); path != "" {
+		var err error
+		if keyringData, err = os.ReadFile(path); err != nil {
+			return err
 		}
-		if filter != nil {
-			return filter(nv)
+	}
+	keyring, err := util.ReadKeyRing(keyringData)
+	if err != nil {
+		return fmt.Errorf("invalid keyring, please check the configuration [%s]: %w", config.KeyNodeKeyring, err)
+	}
+	for _, name := range []string{"SHASUMS256.txt.asc", "SHASUMS256.txt.sig"} {
+		if sums.Signature, err = p.fetch(baseUrl + name); err == nil {
+			sums.SignatureName = name
+			break
 		}
-		return true, nil
 	}
-	list, err := Collection(versions).Filter(defaultFilter)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("signature file not found: %w", err)
 	}
-	sort.Sort(list)
-	return list, nil
+	_, err = util.VerifySignature(keyring, sums.Data, sums.Signature)
+	return err
 }
 
-type Download struct {
-	Version  string
-	BaseName string
-	Ext      string
+func (p *Provider) failover() *module.Failover {
+	return module.NewFailover(p.Keys().Mirror, p.Keys().Proxy)
 }
 
-func (c *Command) ConvertDownload(version string) (*Download, error) {
-	var name string
-	var ext string
-	switch runtime.GOOS {
-	case "windows":
-		ext = "zip"
-		switch runtime.GOARCH {
-		case "amd64":
-			name = fmt.Sprintf("node-%s-win-x64", version) // https://nodejs.org/dist/v22.14.0/node-v22.14.0-win-x64.zip
-		case "386":
-			name = fmt.Sprintf("node-%s-win-x86", version) // https://nodejs.org/dist/v22.14.0/node-v22.14.0-win-x86.zip
-		case "arm64":
-			name = fmt.Sprintf("node-%s-win-arm64", version) // https://nodejs.org/dist/v22.14.0/node-v22.14.0-win-arm64.zip
-		}
-	case "darwin":
-		ext = "tar.xz"
-		//exts = []string{"tar.xz", "tar.gz"}
-		switch runtime.GOARCH {
-		case "amd64":
-			name = fmt.Sprintf("node-%s-darwin-x64", version) // https://nodejs.org/dist/v22.14.0/node-v22.14.0-darwin-x64.tar.gz
-		case "arm64":
-			name = fmt.Sprintf("node-%s-darwin-arm64", version) // https://nodejs.org/dist/v22.14.0/node-v22.14.0-darwin-arm64.tar.gz
-		}
-	case "linux":
-		ext = "tar.gz"
-		// ext = []string{"tar.gz", "tar.xz"}
-		// linux gz > xz
-		switch runtime.GOARCH {
-		case "amd64":
-			name = fmt.Sprintf("node-%s-linux-x64", version) // https://nodejs.org/dist/v22.14.0/node-v22.14.0-linux-x64.tar.xz
-		case "arm", "armv7l":
-			name = fmt.Sprintf("node-%s-linux-armv7l", version) // https://nodejs.org/dist/v22.14.0/node-v22.14.0-linux-armv7l.tar.xz
-		case "arm64":
-			name = fmt.Sprintf("node-%s-linux-arm64", version) // https://nodejs.org/dist/v22.14.0/node-v22.14.0-linux-arm64.tar.xz
-		case "ppc64le":
-			name = fmt.Sprintf("node-%s-linux-ppc64le", version) // https://nodejs.org/dist/v22.14.0/node-v22.14.0-linux-ppc64le.tar.xz
-		case "s390x":
-			name = fmt.Sprintf("node-%s-linux-s390x", version) // https://nodejs.org/dist/v22.14.0/node-v22.14.0-linux-s390x.tar.xz
-		}
+func (p *Provider) fetch(url string) ([]byte, error) {
+	resp, err := module.Get(config.KeyNodeProxy, url, util.WithTimeout(30*time.Second))
+	if err != nil {
+		return nil, err
 	}
-	if name == "" {
-		return nil, fmt.Errorf("unsupported architecture: %s", runtime.GOARCH)
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
+	return io.ReadAll(resp.Body)
+}
 
-	return &Download{
-		Version:  version,
-		BaseName: name,
-		Ext:      ext,
-	}, nil
+func (p *Provider) ArchiveRoot(download *module.Download) string {
+	return download.BaseName
+}
+
+func (p *Provider) BinDir(dir string) string {
+	// linux、darwin解压后放在bin目录中
+	if runtime.GOOS == "windows" {
+		return dir
+	}
+	return filepath.Join(dir, "bin")
 }
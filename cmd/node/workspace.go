@@ -0,0 +1,53 @@
+//go:build (windows && (amd64 || 386 || arm64)) || (linux && (amd64 || arm || armv7l || arm64 || ppc64le || s390x)) || (darwin && (amd64 || arm64))
+
+package node
+
+import (
+	"encoding/json"
+	"jianggujin.com/lvs/cmd/module"
+	"strings"
+)
+
+// packageJson package.json中声明node版本的字段
+type packageJson struct {
+	Engines struct {
+		Node string `json:"node"`
+	} `json:"engines"`
+	Volta struct {
+		Node string `json:"node"`
+	} `json:"volta"`
+}
+
+// WorkspaceFiles 优先级依次为 .nvmrc、.node-version、package.json，
+// package.json中volta.node为固定版本，优先于版本范围engines.node
+func (p *Provider) WorkspaceFiles() []*module.WorkspaceFile {
+	return []*module.WorkspaceFile{
+		{Name: ".nvmrc", Parse: parseNvmrc},
+		{Name: ".node-version", Parse: module.ParseVersionFile},
+		{Name: "package.json", Parse: parsePackageJson},
+	}
+}
+
+// parseNvmrc nvm使用node或stable表示最新版本
+func parseNvmrc(data []byte) (string, error) {
+	version, err := module.ParseVersionFile(data)
+	if err != nil {
+		return "", err
+	}
+	switch strings.ToLower(version) {
+	case "node", "stable":
+		return "latest", nil
+	}
+	return version, nil
+}
+
+func parsePackageJson(data []byte) (string, error) {
+	pkg := &packageJson{}
+	if err := json.Unmarshal(data, pkg); err != nil {
+		return "", err
+	}
+	if version := strings.TrimSpace(pkg.Volta.Node); version != "" {
+		return version, nil
+	}
+	return strings.TrimSpace(pkg.Engines.Node), nil
+}
@@ -0,0 +1,31 @@
+//go:build (windows && (amd64 || 386 || arm64)) || (linux && (amd64 || arm || armv7l || arm64 || ppc64le || s390x)) || (darwin && (amd64 || arm64))
+
+package node
+
+import "testing"
+
+func TestParseWorkspaceFiles(t *testing.T) {
+	tests := []struct {
+		parse    func([]byte) (string, error)
+		content  string
+		expected string
+	}{
+		{parseNvmrc, "# comment\n\nlts/iron # pinned\n", "lts/iron"},
+		{parseNvmrc, "node\n", "latest"},
+		{parsePackageJson, `{"engines":{"node":">=18 <21"}}`, ">=18 <21"},
+		{parsePackageJson, `{"engines":{"node":"^20"},"volta":{"node":"20.11.1"}}`, "20.11.1"},
+		{parsePackageJson, `{"name":"demo"}`, ""},
+	}
+	for _, test := range tests {
+		version, err := test.parse([]byte(test.content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != test.expected {
+			t.Errorf("[%s] expected %s, got %s", test.content, test.expected, version)
+		}
+	}
+	if _, err := parsePackageJson([]byte("{")); err == nil {
+		t.Error("expected error for an invalid package.json")
+	}
+}
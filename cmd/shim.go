@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"jianggujin.com/lvs/cmd/custom"
+	"jianggujin.com/lvs/cmd/module"
+	"jianggujin.com/lvs/internal/config"
+	"jianggujin.com/lvs/internal/invoke"
+	"jianggujin.com/lvs/internal/util"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+type ReshimCommand struct {
+}
+
+type ShimExecCommand struct {
+}
+
+func init() {
+	util.AddCommand(rootCmd, &ReshimCommand{})
+	util.AddCommand(rootCmd, &ShimExecCommand{})
+}
+
+func (command *ReshimCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reshim",
+		Short: "Regenerate the launchers of all installed versions in the shims directory",
+		Long: fmt.Sprintf(`Regenerate the launchers of all installed versions in the shims directory.
+Run it after new executables are installed, e.g. npm i -g. The launchers are used when %s is %s.`,
+			config.KeyLvsVersionMode, config.VersionModeShims),
+		Args: cobra.NoArgs,
+		RunE: command.RunE,
+	}
+	return cmd
+}
+
+func (command *ReshimCommand) RunE(_ *cobra.Command, _ []string) error {
+	home := module.ShimsHome()
+	// 清除已卸载版本的启动器
+	if err := os.RemoveAll(home); err != nil {
+		return util.WrapErrorMsg("clean shims directory [%s] error", home).SetErr(err)
+	}
+	if err := os.MkdirAll(home, os.ModePerm); err != nil {
+		return util.WrapErrorMsg("create shims directory [%s] error", home).SetErr(err)
+	}
+	// 同名的可执行文件只为第一个模块生成启动器，内置模块优先于自定义模块
+	for _, c := range module.Commands() {
+		binaries, conflicts, err := c.Reshim()
+		if err != nil {
+			return util.WrapErrorMsg("reshim [%s] error", c.Name()).SetErr(err)
+		}
+		printShims(c.Name(), binaries, conflicts)
+	}
+	for _, c := range custom.Customs() {
+		binaries, conflicts, err := c.Reshim()
+		if err != nil {
+			return util.WrapErrorMsg("reshim [%s] error", c.Name).SetErr(err)
+		}
+		printShims(c.Name, binaries, conflicts)
+	}
+	if !module.ShimMode() {
+		fmt.Printf("%s is not %s, run '%s config %s %s' and '%s install' to use the launchers\n", config.KeyLvsVersionMode,
+			config.VersionModeShims, config.Name(), config.KeyLvsVersionMode, config.VersionModeShims, config.Name())
+	}
+	return nil
+}
+
+func printShims(name string, binaries []string, conflicts []*module.ShimConflict) {
+	if len(binaries) > 0 {
+		fmt.Printf("%s: %s\n", name, strings.Join(binaries, ", "))
+	}
+	for _, conflict := range conflicts {
+		fmt.Printf("  conflict: [%s] is already provided by [%s], skipped\n", conflict.Tool, conflict.Module)
+	}
+}
+
+func (command *ShimExecCommand) Init() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "shim-exec module command [args...]",
+		Short:              "Used by the launchers, execute the command with the version resolved from the environment variable, workspace or global",
+		Args:               cobra.MinimumNArgs(2),
+		Hidden:             true,
+		DisableFlagParsing: true,
+		RunE:               command.RunE,
+	}
+	return cmd
+}
+
+func (command *ShimExecCommand) RunE(_ *cobra.Command, args []string) error {
+	name, tool := args[0], args[1]
+	dir, binDirs, envKey, err := shimTarget(name)
+	if err != nil {
+		return util.WrapErrorMsg("%s: resolve version of [%s] error", tool, name).SetErr(err)
+	}
+	var execPath string
+	for _, binDir := range binDirs {
+		if path := filepath.Join(binDir, tool); util.ExecExists(path) {
+			execPath = path
+			break
+		}
+	}
+	// 不能从PATH中查找，否则会再次执行启动器
+	if execPath == "" {
+		return util.WrapErrorMsg("%s: command not found in [%s], run '%s reshim' to regenerate the launchers", tool, dir, config.Name())
+	}
+	env := os.Environ()
+	if envKey != "" {
+		env = setEnv(env, envKey, dir)
+	}
+	paths := append(binDirs, filepath.SplitList(getEnv(env, "PATH"))...)
+	env = setEnv(env, "PATH", strings.Join(paths, string(filepath.ListSeparator)))
+
+	err = invoke.GetInvoker().CommandOptions(execPath, args[2:], invoke.WithStd(), invoke.WithEnv(env))
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	return err
+}
+
+// shimTarget 返回模块版本目录、可执行文件目录与指向版本目录的环境变量，内置模块优先于自定义模块
+func shimTarget(name string) (string, []string, string, error) {
+	if c := module.Lookup(name); c != nil {
+		dir, err := c.ShimDir()
+		if err != nil {
+			return "", nil, "", err
+		}
+		var envKey string
+		if m := config.Modules[name]; m != nil {
+			envKey = m.SymlinkEnvKey
+		}
+		return dir, []string{c.BinDir(dir)}, envKey, nil
+	}
+	if c := custom.Lookup(name); c != nil {
+		dir, err := c.ShimDir()
+		if err != nil {
+			return "", nil, "", err
+		}
+		return dir, c.BinDirs(dir), c.SymlinkEnvKey, nil
+	}
+	return "", nil, "", fmt.Errorf("module [%s] not found", name)
+}
+
+// getEnv 获取环境变量，windows中环境变量名称不区分大小写
+func getEnv(env []string, key string) string {
+	for i := len(env) - 1; i >= 0; i-- {
+		if k, v, ok := strings.Cut(env[i], "="); ok && envKeyEqual(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+// setEnv 设置环境变量，已存在的同名环境变量会被移除
+func setEnv(env []string, key, value string) []string {
+	result := make([]string, 0, len(env)+1)
+	for _, kv := range env {
+		if k, _, ok := strings.Cut(kv, "="); ok && envKeyEqual(k, key) {
+			continue
+		}
+		result = append(result, kv)
+	}
+	return append(result, key+"="+value)
+}
+
+func envKeyEqual(a, b string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
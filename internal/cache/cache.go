@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"jianggujin.com/lvs/internal/config"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry 缓存的归档文件，按sha256摘要存放在 CACHE_HOME/<摘要>/<文件名>
+type Entry struct {
+	Digest  string
+	Name    string
+	Path    string
+	Size    int64
+	ModTime time.Time // 最后一次使用时间
+}
+
+func Home() string {
+	return config.GetPath(config.KeyLvsCacheHome)
+}
+
+func validDigest(digest string) bool {
+	if len(digest) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(digest)
+	return err == nil
+}
+
+func readEntry(dir, digest string) (*Entry, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, digest))
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range entries {
+		if item.IsDir() {
+			continue
+		}
+		info, err := item.Info()
+		if err != nil {
+			return nil, err
+		}
+		return &Entry{
+			Digest:  digest,
+			Name:    item.Name(),
+			Path:    filepath.Join(dir, digest, item.Name()),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}, nil
+	}
+	return nil, os.ErrNotExist
+}
+
+// Lookup 根据摘要查找缓存，不存在时返回nil，命中时更新最后使用时间
+func Lookup(digest string) (*Entry, error) {
+	digest = strings.ToLower(strings.TrimSpace(digest))
+	if !validDigest(digest) {
+		return nil, nil
+	}
+	entry, err := readEntry(Home(), digest)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if err = os.Chtimes(entry.Path, now, now); err == nil {
+		entry.ModTime = now
+	}
+	return entry, nil
+}
+
+// LookupName 根据文件名查找最近使用的缓存，不存在时返回nil，命中时更新最后使用时间，
+// 缓存目录的名称即为存入时已校验的摘要，使用前可以据此重新校验文件
+func LookupName(name string) (*Entry, error) {
+	entries, err := List()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.Name == name {
+			return Lookup(entry.Digest)
+		}
+	}
+	return nil, nil
+}
+
+// Store 将已校验的文件移动到缓存中
+func Store(digest, name, src string) (*Entry, error) {
+	digest = strings.ToLower(strings.TrimSpace(digest))
+	if !validDigest(digest) {
+		return nil, fmt.Errorf("invalid sha256 digest: %s", digest)
+	}
+	dir := filepath.Join(Home(), digest)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	dest := filepath.Join(dir, name)
+	if err := os.Rename(src, dest); err != nil {
+		// 临时目录与缓存目录可能不在同一个分区
+		if err = copyFile(src, dest); err != nil {
+			_ = os.Remove(dest)
+			return nil, err
+		}
+		_ = os.Remove(src)
+	}
+	now := time.Now()
+	_ = os.Chtimes(dest, now, now)
+	return readEntry(Home(), digest)
+}
+
+func copyFile(src, dest string) error {
+	reader, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	writer, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+	_, err = io.Copy(writer, reader)
+	return err
+}
+
+// List 列出所有缓存，按最后使用时间倒序排列
+func List() ([]*Entry, error) {
+	home := Home()
+	items, err := os.ReadDir(home)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []*Entry
+	for _, item := range items {
+		if !item.IsDir() || !validDigest(item.Name()) {
+			continue
+		}
+		entry, err := readEntry(home, item.Name())
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime.After(entries[j].ModTime)
+	})
+	return entries, nil
+}
+
+// Remove 删除缓存
+func Remove(entry *Entry) error {
+	return os.RemoveAll(filepath.Join(Home(), entry.Digest))
+}
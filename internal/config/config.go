@@ -4,11 +4,11 @@ import (
 	"fmt"
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
-	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 )
 
 const (
@@ -18,25 +18,34 @@ const (
 )
 
 const (
-	KeyLvsDataHome       = "DATA_HOME"       // 程序数据目录
-	KeyLvsProxy          = "PROXY"           // 全局代理配置
-	KeyLvsTempHome       = "TEMP_HOME"       // 临时文件目录
-	KeyLvsDefaultCommand = "DEFAULT_COMMAND" // 默认执行命令
+	KeyLvsDataHome          = "DATA_HOME"          // 程序数据目录
+	KeyLvsProxy             = "PROXY"              // 全局代理配置
+	KeyLvsTempHome          = "TEMP_HOME"          // 临时文件目录
+	KeyLvsTempExpire        = "TEMP_EXPIRE"        // 未完成下载文件的保留时长，超过后清理
+	KeyLvsCacheHome         = "CACHE_HOME"         // 归档文件缓存目录
+	KeyLvsIndexTtl          = "INDEX_TTL"          // 远程版本索引缓存的有效期，超过后重新验证
+	KeyLvsDefaultCommand    = "DEFAULT_COMMAND"    // 默认执行命令
+	KeyLvsMirrorStrategy    = "MIRROR_STRATEGY"    // 多个镜像地址的选择策略，order或latency
+	KeyLvsWorkspaceBoundary = "WORKSPACE_BOUNDARY" // 向上查找工作空间版本文件的边界，多个值使用逗号分隔
+	KeyLvsVersionMode       = "VERSION_MODE"       // 版本切换方式，symlink或shims
 
 	KeyShellConfigPath = "SHELL_CONFIG_PATH" // Shell配置文件 非windows生效
 
 	KeyNodeSymlink = "NODE_SYMLINK"     // node.js软链的文件位置
 	KeyNodeHome    = "NODE_HOME"        // node.js程序安装目录
 	KeyNodeProxy   = "NODE_PROXY"       // node.js代理配置
-	KeyNodeMirror  = "NODE_NODE_MIRROR" // node.js镜像地址
+	KeyNodeMirror  = "NODE_NODE_MIRROR" // node.js镜像地址，多个地址使用逗号分隔
+	KeyNodeKeyring = "NODE_KEYRING"     // node.js发布密钥环文件，为空时使用内置密钥环
 
 	KeyGoSymlink = "GO_SYMLINK" // go软链的文件位置
 	KeyGoHome    = "GO_HOME"    // go程序安装目录
 	KeyGoProxy   = "GO_PROXY"   // go代理配置
-	KeyGoMirror  = "GO_MIRROR"  // go镜像地址
+	KeyGoMirror  = "GO_MIRROR"  // go镜像地址，多个地址使用逗号分隔
 
 	KeyNodeAliasPrefix = "ALIAS_NODE_" // node.js版本别名
 	KeyGoAliasPrefix   = "ALIAS_GO_"   // go版本别名
+	KeyNodeGlobal      = "GLOBAL_NODE" // node.js全局默认版本，工作空间未声明版本时使用
+	KeyGoGlobal        = "GLOBAL_GO"   // go全局默认版本，工作空间未声明版本时使用
 	KeyWorkspaceSuffix = ".lvsrc"      // 工作空间使用版本后缀
 )
 
@@ -49,6 +58,9 @@ type Module struct {
 
 var Modules = make(map[string]*Module)
 
+// Offline 离线模式，仅使用缓存的版本索引与已安装的版本，由全局参数--offline设置
+var Offline bool
+
 const (
 	ModuleNode = "node"
 	ModuleGo   = "go"
@@ -67,8 +79,23 @@ const (
 	defaultLvsConfigType = "yaml"
 	defaultLvsDataHome   = "~/.lvs"
 	defaultLvsTempHome   = defaultLvsDataHome + "/temp"
+	defaultLvsTempExpire = "168h"
+	defaultLvsCacheHome  = defaultLvsDataHome + "/cache"
+	defaultLvsIndexTtl   = "1h"
 	DefaultLvsCustomFile = "custom.json"
 
+	MirrorStrategyOrder   = "order"   // 按照配置顺序，优先使用上次可用的镜像
+	MirrorStrategyLatency = "latency" // 按照测量的响应延迟
+
+	BoundaryVcs  = "vcs"  // 包含.git、.hg或.svn的版本控制根目录
+	BoundaryHome = "home" // 用户主目录
+	BoundaryRoot = "root" // 文件系统根目录，即不限制
+
+	defaultLvsWorkspaceBoundary = BoundaryVcs + "," + BoundaryHome
+
+	VersionModeSymlink = "symlink" // 通过软链切换全局版本
+	VersionModeShims   = "shims"   // 通过启动器在运行时解析版本
+
 	defaultNodeHome       = defaultLvsDataHome + "/repository/nodejs"
 	defaultNodeSymlink    = defaultLvsDataHome + "/symlink/nodejs"
 	defaultNodeNodeMirror = "https://nodejs.org/dist/"
@@ -84,7 +111,13 @@ const (
 func init() {
 	viper.SetDefault(KeyLvsDataHome, env(KeyLvsDataHome, defaultLvsDataHome, false))
 	viper.SetDefault(KeyLvsTempHome, env(KeyLvsTempHome, defaultLvsTempHome, false))
+	viper.SetDefault(KeyLvsTempExpire, env(KeyLvsTempExpire, defaultLvsTempExpire, false))
+	viper.SetDefault(KeyLvsCacheHome, env(KeyLvsCacheHome, defaultLvsCacheHome, false))
+	viper.SetDefault(KeyLvsIndexTtl, env(KeyLvsIndexTtl, defaultLvsIndexTtl, false))
 	viper.SetDefault(KeyLvsDefaultCommand, env(KeyLvsDefaultCommand, "", false))
+	viper.SetDefault(KeyLvsMirrorStrategy, env(KeyLvsMirrorStrategy, MirrorStrategyOrder, false))
+	viper.SetDefault(KeyLvsWorkspaceBoundary, env(KeyLvsWorkspaceBoundary, defaultLvsWorkspaceBoundary, false))
+	viper.SetDefault(KeyLvsVersionMode, env(KeyLvsVersionMode, VersionModeSymlink, false))
 
 	viper.SetDefault(KeyNodeHome, env(KeyNodeHome, defaultNodeHome, false))
 	viper.SetDefault(KeyNodeSymlink, env(KeyNodeSymlink, defaultNodeSymlink, false))
@@ -176,6 +209,21 @@ func GetStringWithDefault(key string, defValue string) string {
 	return defValue
 }
 
+// GetList 读取逗号分隔的配置，忽略空项
+func GetList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(viper.GetString(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func GetDuration(key string) time.Duration {
+	return viper.GetDuration(key)
+}
+
 func GetPath(key string) string {
 	path := viper.GetString(key)
 	if path == "" {
@@ -208,24 +256,6 @@ func Filter(filter func(string) bool) map[string]string {
 	return m
 }
 
-func GetWorkspaceUseVersion(module string) (string, error) {
-	dir, err := os.Getwd()
-	if err != nil {
-		return "", err
-	}
-	path := filepath.Join(dir, fmt.Sprintf("%s%s", module, KeyWorkspaceSuffix))
-	file, err := os.Open(path)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-	data, err := io.ReadAll(file)
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(string(data)), err
-}
-
 func env(name, defValue string, raw bool) string {
 	if !raw {
 		name = fmt.Sprintf("%s%s", EnvLvsPrefix, name)
@@ -11,6 +11,7 @@ import (
 	"jianggujin.com/lvs/internal/shell"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -18,60 +19,92 @@ func Install(envKeyValues map[string]string, pathValues []string) error {
 	if (envKeyValues == nil || len(envKeyValues) == 0) && len(pathValues) == 0 {
 		return nil
 	}
-	adapter := shell.NewShellAdapter(config.GetString(config.KeyShellType), config.GetPath(config.KeyShellConfigPath))
-	// 备份文件
-	_, err := os.Stat(adapter.ConfigPath)
-	var data []byte
-	if err == nil {
-		if data, err = backup(adapter.ConfigPath); err != nil {
-			return err
-		}
+	return update(envKeyValues, pathValues, nil, func(adapter *shell.ShellAdapter, data []byte) ([]byte, error) {
+		return adapter.SetEnvs(data, envKeyValues, pathValues)
+	})
+}
+
+func Uninstall(envKeys []string, pathValues []string) error {
+	if len(envKeys) == 0 && len(pathValues) == 0 {
+		return nil
 	}
-	if data, err = adapter.SetEnvs(data, envKeyValues, pathValues); err != nil {
-		return err
+	envKeyValues := make(map[string]string)
+	for _, key := range envKeys {
+		// 取值未知，仅迁移与内置模块取值一致的语句
+		envKeyValues[key] = ""
 	}
+	return update(envKeyValues, pathValues, nil, func(adapter *shell.ShellAdapter, data []byte) ([]byte, error) {
+		return adapter.DelEnvs(data, envKeys, pathValues)
+	})
+}
 
-	writer, err := os.Create(adapter.ConfigPath)
+// InstallLines 在Shell配置文件的LVS配置块中添加不存在的语句
+func InstallLines(lines ...string) error {
+	return update(nil, nil, lines, func(adapter *shell.ShellAdapter, data []byte) ([]byte, error) {
+		return adapter.AddLines(data, lines)
+	})
+}
+
+// update 修改Shell配置文件中由LVS管理的配置块，修改前将旧版本LVS逐行写入的语句移动到配置块中，
+// 配置块之外的内容保持不变，内容变化时才会备份并写入文件
+func update(envKeyValues map[string]string, pathValues []string, lines []string, fn func(*shell.ShellAdapter, []byte) ([]byte, error)) error {
+	adapter := shell.NewShellAdapter(config.GetString(config.KeyShellType), config.GetPath(config.KeyShellConfigPath))
+	origin, err := os.ReadFile(adapter.ConfigPath())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	envValues, pathValues := ownedEnvs(envKeyValues, pathValues)
+	data, err := adapter.Migrate(origin, envValues, pathValues, lines)
 	if err != nil {
 		return err
 	}
-	defer writer.Close()
-	if _, err = writer.Write(bytes.TrimSpace(data)); err != nil {
+	if data, err = fn(adapter, data); err != nil {
 		return err
 	}
-
-	_, _ = invoke.GetInvoker().Command("source", adapter.ConfigPath)
-	return err
-}
-
-func Uninstall(envKeys []string, pathValues []string) error {
-	if len(envKeys) == 0 && len(pathValues) == 0 {
+	if bytes.Equal(data, origin) {
 		return nil
 	}
-	adapter := shell.NewShellAdapter(config.GetString(config.KeyShellType), config.GetPath(config.KeyShellConfigPath))
 	// 备份文件
-	_, err := os.Stat(adapter.ConfigPath)
-	var data []byte
-	if err == nil {
-		if data, err = backup(adapter.ConfigPath); err != nil {
+	if len(origin) > 0 {
+		if _, err = backup(adapter.ConfigPath()); err != nil {
 			return err
 		}
 	}
-	if data, err = adapter.DelEnvs(data, envKeys, pathValues); err != nil {
+	// nushell、pwsh等终端的配置目录可能不存在
+	if err = os.MkdirAll(filepath.Dir(adapter.ConfigPath()), os.ModePerm); err != nil {
 		return err
 	}
-
-	writer, err := os.Create(adapter.ConfigPath)
-	if err != nil {
-		return err
-	}
-	defer writer.Close()
-	if _, err = writer.Write(bytes.TrimSpace(data)); err != nil {
+	if err = os.WriteFile(adapter.ConfigPath(), data, 0644); err != nil {
 		return err
 	}
 
-	_, _ = invoke.GetInvoker().Command("source", adapter.ConfigPath)
-	return err
+	_, _ = invoke.GetInvoker().Command("source", adapter.ConfigPath())
+	return nil
+}
+
+// ownedEnvs 旧版本LVS可能写入的环境变量取值与PATH目录，包括本次写入的取值、LVS_HOME与所有内置模块，
+// LVS_前缀的环境变量只能由LVS写入，取值为nil表示不比较取值
+func ownedEnvs(envKeyValues map[string]string, pathValues []string) (map[string][]string, []string) {
+	envValues := make(map[string][]string)
+	add := func(key, value string) {
+		if strings.HasPrefix(key, config.EnvLvsPrefix) {
+			envValues[key] = nil
+		} else if value != "" {
+			envValues[key] = append(envValues[key], value)
+		}
+	}
+	add(config.EnvLvsHome, "")
+	for key, value := range envKeyValues {
+		add(key, value)
+	}
+	pathValues = append([]string{fmt.Sprintf("%%%s%%", config.EnvLvsHome)}, pathValues...)
+	for _, module := range config.Modules {
+		for key, value := range module.EnvKeyValues {
+			add(key, value)
+		}
+		pathValues = append(pathValues, module.PathValues...)
+	}
+	return envValues, pathValues
 }
 
 func backup(path string) ([]byte, error) {
@@ -28,6 +28,12 @@ func WithDir(dir string) Option {
 	}
 }
 
+func WithEnv(env []string) Option {
+	return func(cmd *exec.Cmd) {
+		cmd.Env = env
+	}
+}
+
 type Invoker interface {
 	Command(string, ...string) ([]byte, error)
 	CommandWithContext(context.Context, string, ...string) ([]byte, error)
@@ -0,0 +1,328 @@
+//go:build !windows
+
+package shell
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	BlockBegin = "# >>> lvs >>>" // LVS管理的配置块开始标记
+	BlockEnd   = "# <<< lvs <<<" // LVS管理的配置块结束标记
+
+	blockNotice = "# Contents within this block are managed by lvs, changes will be overwritten"
+)
+
+// Block 配置文件中由LVS管理的配置块，每次修改时完整重新生成，配置块之外的内容保持不变
+type Block struct {
+	Envs  map[string]string // 环境变量
+	Paths []string          // 添加到PATH前的目录，如%NODE_HOME%/bin，靠前的目录优先
+	Lines []string          // 其他语句，如加载补全脚本的语句
+}
+
+func (b *Block) empty() bool {
+	return len(b.Envs) == 0 && len(b.Paths) == 0 && len(b.Lines) == 0
+}
+
+// addPaths 将目录添加到最前面，已存在的目录会被移动
+func (b *Block) addPaths(paths []string) {
+	b.removePaths(paths)
+	b.Paths = append(append([]string{}, paths...), b.Paths...)
+}
+
+func (b *Block) removePaths(paths []string) {
+	var result []string
+	for _, path := range b.Paths {
+		if !containsString(paths, path) {
+			result = append(result, path)
+		}
+	}
+	b.Paths = result
+}
+
+// SetEnvs 在配置块中设置环境变量并将目录添加到PATH最前面
+func (a *ShellAdapter) SetEnvs(data []byte, envKeyValues map[string]string, pathValues []string) ([]byte, error) {
+	return a.UpdateBlock(data, func(block *Block) {
+		for key, value := range envKeyValues {
+			block.Envs[key] = value
+		}
+		block.addPaths(pathValues)
+	})
+}
+
+// DelEnvs 从配置块中移除环境变量与PATH中的目录
+func (a *ShellAdapter) DelEnvs(data []byte, envKeys []string, pathValues []string) ([]byte, error) {
+	return a.UpdateBlock(data, func(block *Block) {
+		for _, key := range envKeys {
+			delete(block.Envs, key)
+		}
+		block.removePaths(pathValues)
+	})
+}
+
+// AddLines 在配置块中添加不存在的语句
+func (a *ShellAdapter) AddLines(data []byte, lines []string) ([]byte, error) {
+	return a.UpdateBlock(data, func(block *Block) {
+		for _, line := range lines {
+			if !containsString(block.Lines, line) {
+				block.Lines = append(block.Lines, line)
+			}
+		}
+	})
+}
+
+// UpdateBlock 解析并修改配置块后重新生成，配置块不存在时添加到文件末尾，修改后为空时移除配置块
+func (a *ShellAdapter) UpdateBlock(data []byte, update func(*Block)) ([]byte, error) {
+	before, content, after, found := splitBlock(data)
+	block := a.parseBlock(content)
+	update(block)
+	if !found {
+		if block.empty() {
+			return data, nil
+		}
+		if len(before) > 0 && !bytes.HasSuffix(before, []byte("\n")) {
+			before = append(before, '\n')
+		}
+	}
+	var buf bytes.Buffer
+	buf.Write(before)
+	buf.WriteString(a.formatBlock(block))
+	buf.Write(after)
+	return buf.Bytes(), nil
+}
+
+// Migrate 将配置块之外由旧版本LVS逐行写入的语句移动到配置块中，仅移动取值与envValues一致的环境变量语句、
+// 完全由pathValues中的目录组成的PATH语句以及与lines相同的语句，其他语句保持不变。
+// envValues的键为环境变量名称，值为LVS可能写入的取值，为nil时表示任意取值均由LVS写入，如LVS_HOME
+func (a *ShellAdapter) Migrate(data []byte, envValues map[string][]string, pathValues []string, lines []string) ([]byte, error) {
+	before, content, after, found := splitBlock(data)
+	migrated := &Block{Envs: map[string]string{}}
+	items := a.replacePathValues(pathValues)
+	before, position := a.extractLines(before, migrated, envValues, items, lines)
+	after, _ = a.extractLines(after, migrated, envValues, items, lines)
+	if migrated.empty() {
+		return data, nil
+	}
+	block := a.parseBlock(content)
+	for key, value := range migrated.Envs {
+		if _, ok := block.Envs[key]; !ok {
+			block.Envs[key] = value
+		}
+	}
+	for _, path := range migrated.Paths {
+		if !containsString(block.Paths, path) {
+			block.Paths = append(block.Paths, path)
+		}
+	}
+	for _, line := range migrated.Lines {
+		if !containsString(block.Lines, line) {
+			block.Lines = append(block.Lines, line)
+		}
+	}
+	var buf bytes.Buffer
+	if !found {
+		// 配置块位于第一条被移动的语句处
+		before, after = before[:position], before[position:]
+	}
+	buf.Write(before)
+	buf.WriteString(a.formatBlock(block))
+	buf.Write(after)
+	return buf.Bytes(), nil
+}
+
+// extractLines 移除旧版本LVS写入的语句并记录到block中，返回剩余内容与第一条被移除语句的位置
+func (a *ShellAdapter) extractLines(data []byte, block *Block, envValues map[string][]string, items pathValues, lines []string) ([]byte, int) {
+	re := regexp.MustCompile(a.matchPattern())
+	var buf bytes.Buffer
+	position := -1
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if a.extractLine(re, strings.TrimSpace(string(line)), block, envValues, items, lines) {
+			if position < 0 {
+				position = buf.Len()
+			}
+			continue
+		}
+		buf.Write(line)
+	}
+	if position < 0 {
+		position = buf.Len()
+	}
+	return buf.Bytes(), position
+}
+
+func (a *ShellAdapter) extractLine(re *regexp.Regexp, line string, block *Block, envValues map[string][]string, items pathValues, lines []string) bool {
+	if line == "" {
+		return false
+	}
+	if containsString(lines, line) {
+		block.Lines = append(block.Lines, line)
+		return true
+	}
+	if !strings.HasPrefix(line, a.SetPrefix) {
+		return false
+	}
+	_, envKey, envValue, ok := a.matchEnv(re, line)
+	if !ok {
+		return false
+	}
+	if envKey != "PATH" {
+		values, ok := envValues[envKey]
+		envValue = a.unquote(envValue)
+		// 同名但取值不同的语句由用户写入，如自定义的GOPATH
+		if !ok || (values != nil && !containsPath(values, envValue)) {
+			return false
+		}
+		block.Envs[envKey] = envValue
+		return true
+	}
+	values, ok := a.splitPath(envValue)
+	if !ok {
+		return false
+	}
+	var paths []string
+	for _, value := range values {
+		if a.isEmptyOrOnlyPath([]string{value}) {
+			continue
+		}
+		item := items.find(value)
+		if item == nil {
+			// 包含其他目录的PATH语句不是由LVS写入的
+			return false
+		}
+		paths = append(paths, item.input)
+	}
+	if len(paths) == 0 {
+		return false
+	}
+	// 后写入的PATH语句优先
+	block.addPaths(paths)
+	return true
+}
+
+// splitBlock 将配置文件拆分为配置块之前的内容、配置块中的语句与配置块之后的内容
+func splitBlock(data []byte) ([]byte, []byte, []byte, bool) {
+	begin := lineIndex(data, BlockBegin, 0)
+	if begin < 0 {
+		return data, nil, nil, false
+	}
+	contentStart := lineEnd(data, begin)
+	end := lineIndex(data, BlockEnd, contentStart)
+	if end < 0 {
+		// 缺少结束标记时视为配置块延续到文件末尾
+		return data[:begin], data[contentStart:], nil, true
+	}
+	return data[:begin], data[contentStart:end], data[lineEnd(data, end):], true
+}
+
+// lineIndex 查找从from开始内容为marker的行的起始位置
+func lineIndex(data []byte, marker string, from int) int {
+	for start := from; start < len(data); {
+		end := lineEnd(data, start)
+		if strings.TrimSpace(string(data[start:end])) == marker {
+			return start
+		}
+		start = end
+	}
+	return -1
+}
+
+// lineEnd 行结束的位置，包含换行符
+func lineEnd(data []byte, start int) int {
+	if index := bytes.IndexByte(data[start:], '\n'); index >= 0 {
+		return start + index + 1
+	}
+	return len(data)
+}
+
+// parseBlock 解析配置块中的语句，注释会被忽略，无法识别的语句作为其他语句保留
+func (a *ShellAdapter) parseBlock(content []byte) *Block {
+	block := &Block{Envs: map[string]string{}}
+	re := regexp.MustCompile(a.matchPattern())
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, a.SetPrefix) {
+			if _, envKey, envValue, ok := a.matchEnv(re, line); ok {
+				if envKey != "PATH" {
+					block.Envs[envKey] = a.unquote(envValue)
+					continue
+				}
+				if values, ok := a.splitPath(envValue); ok {
+					for _, value := range values {
+						if !a.isEmptyOrOnlyPath([]string{value}) {
+							block.Paths = append(block.Paths, a.pathInput(value))
+						}
+					}
+					continue
+				}
+			}
+		}
+		block.Lines = append(block.Lines, line)
+	}
+	return block
+}
+
+// formatBlock 生成配置块，环境变量按照名称排序，PATH位于环境变量之后，配置块为空时返回空
+func (a *ShellAdapter) formatBlock(block *Block) string {
+	if block.empty() {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString(BlockBegin + "\n")
+	buf.WriteString(blockNotice + "\n")
+	keys := make([]string, 0, len(block.Envs))
+	for key := range block.Envs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		buf.WriteString(a.exportEnv(key, a.escapeEnvValue(block.Envs[key])))
+	}
+	if len(block.Paths) > 0 {
+		buf.WriteString(a.exportEnv("PATH", a.replacePathValues(block.Paths).toPath(a)))
+	}
+	for _, line := range block.Lines {
+		buf.WriteString(line + "\n")
+	}
+	buf.WriteString(BlockEnd + "\n")
+	return buf.String()
+}
+
+// unquote 移除环境变量值两端的双引号
+func (a *ShellAdapter) unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// pathInput 将引用变量转换为%KEY%的形式，如$NODE_HOME/bin转换为%NODE_HOME%/bin
+func (a *ShellAdapter) pathInput(value string) string {
+	re := regexp.MustCompile(regexp.QuoteMeta(a.Prefix) + `([A-Za-z_][A-Za-z0-9_]*)` + regexp.QuoteMeta(a.Suffix))
+	return re.ReplaceAllString(value, "%${1}%")
+}
+
+// containsPath 比较清理后的路径，忽略末尾的路径分隔符等差异
+func containsPath(list []string, value string) bool {
+	for _, item := range list {
+		if item == value || filepath.Clean(item) == filepath.Clean(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
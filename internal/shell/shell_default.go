@@ -3,8 +3,6 @@
 package shell
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
 	"github.com/mitchellh/go-homedir"
 	"jianggujin.com/lvs/internal/invoke"
@@ -14,6 +12,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 )
 
@@ -35,6 +34,12 @@ func ShellType() string {
 		return "fish"
 	case strings.Contains(shellName, "csh"), strings.Contains(shellName, "tcsh"):
 		return "csh"
+	case shellName == "nu":
+		return "nu"
+	case strings.Contains(shellName, "pwsh"), strings.Contains(shellName, "powershell"):
+		return "pwsh"
+	case strings.Contains(shellName, "elvish"):
+		return "elvish"
 	default:
 		return "unknown"
 	}
@@ -71,6 +76,21 @@ func ShellConfigPath(shellType string) string {
 		files = []string{"~/.config/fish/config.fish", "/etc/fish/config.fish"}
 	case "csh":
 		files = []string{"~/.cshrc", "/etc/csh.cshrc"}
+	case "nu":
+		if path := queryShellPath("nu", "-c", "$nu.env-path"); path != "" {
+			return path
+		}
+		files = []string{"~/.config/nushell/env.nu"}
+		if runtime.GOOS == "darwin" {
+			files = []string{"~/.config/nushell/env.nu", "~/Library/Application Support/nushell/env.nu"}
+		}
+	case "pwsh":
+		if path := queryShellPath("pwsh", "-NoProfile", "-Command", "$PROFILE"); path != "" {
+			return path
+		}
+		files = []string{"~/.config/powershell/Microsoft.PowerShell_profile.ps1"}
+	case "elvish":
+		files = []string{"~/.elvish/rc.elv", "~/.config/elvish/rc.elv"}
 	default:
 		files = []string{"/etc/profile"}
 	}
@@ -83,23 +103,33 @@ func ShellConfigPath(shellType string) string {
 	return files[len(files)-1]
 }
 
+// queryShellPath 通过Shell获取配置文件路径，如nushell的$nu.env-path、pwsh的$PROFILE，Shell不可用时返回空
+func queryShellPath(name string, args ...string) string {
+	output, err := invoke.GetInvoker().Command(name, args...)
+	if err != nil {
+		return ""
+	}
+	path := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(path) {
+		return ""
+	}
+	return path
+}
+
+// NewShellAdapter 创建Shell适配器，shellConfigPath为空时在首次使用时检测配置文件路径
 func NewShellAdapter(shellType, shellConfigPath string) *ShellAdapter {
 	if shellType == "" {
 		shellType = ShellType()
 	}
+	adapter := newShellAdapter(shellType)
+	adapter.shellType, adapter.configPath = shellType, shellConfigPath
+	return adapter
+}
 
-	getPath := func(sType string) string {
-		if shellConfigPath == "" {
-			shellConfigPath = ShellConfigPath(sType)
-		}
-		shellConfigPath, _ = homedir.Expand(shellConfigPath)
-		return shellConfigPath
-	}
-
+func newShellAdapter(shellType string) *ShellAdapter {
 	switch shellType {
 	case "zsh":
 		return &ShellAdapter{
-			ConfigPath:  getPath(shellType),
 			KvSeparator: "=",
 			VSeparator:  ":",
 			Prefix:      "$",
@@ -107,7 +137,6 @@ func NewShellAdapter(shellType, shellConfigPath string) *ShellAdapter {
 		}
 	case "bash":
 		return &ShellAdapter{
-			ConfigPath:  getPath(shellType),
 			KvSeparator: "=",
 			VSeparator:  ":",
 			Prefix:      "$",
@@ -115,7 +144,6 @@ func NewShellAdapter(shellType, shellConfigPath string) *ShellAdapter {
 		}
 	case "fish":
 		return &ShellAdapter{
-			ConfigPath:     getPath(shellType),
 			KvSeparator:    " ",
 			VSeparator:     " ",
 			Prefix:         "$",
@@ -125,7 +153,6 @@ func NewShellAdapter(shellType, shellConfigPath string) *ShellAdapter {
 		}
 	case "csh":
 		return &ShellAdapter{
-			ConfigPath:  getPath(shellType),
 			KvSeparator: " ",
 			VSeparator:  ":",
 			Wrappers: map[string]string{
@@ -135,9 +162,55 @@ func NewShellAdapter(shellType, shellConfigPath string) *ShellAdapter {
 			Suffix:    "}",
 			SetPrefix: "setenv",
 		}
+	case "nu":
+		// $env.PATH = ($env.PATH | split row (char esep) | prepend [$"($env.LVS_HOME)"])
+		return &ShellAdapter{
+			VSeparator:  " ",
+			Prefix:      "($env.",
+			Suffix:      ")",
+			SetPrefix:   "$env.",
+			SetFormat:   "$env.%s = %s",
+			SetPattern:  `^\$env\.([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`,
+			Quote:       `"%s"`,
+			ItemFormat:  `"%s"`,
+			RefFormat:   `$"%s"`,
+			PathFormat:  "($env.PATH | split row (char esep) | prepend [%s])",
+			PathPattern: `^\(\$env\.PATH \| split row \(char esep\) \| prepend \[(.*)\]\)$`,
+			ListFormat:  "[%s]",
+		}
+	case "pwsh":
+		// $env:PATH = "${env:LVS_HOME}:${env:PATH}"，变量名称后的冒号会被解析为作用域，因此使用大括号
+		return &ShellAdapter{
+			VSeparator: ":",
+			Wrappers: map[string]string{
+				"$env:":   "",
+				"$($env:": ")",
+			},
+			Prefix:      "${env:",
+			Suffix:      "}",
+			SetPrefix:   "$env:",
+			SetFormat:   "$env:%s = %s",
+			SetPattern:  `^\$env:([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`,
+			Quote:       `"%s"`,
+			ItemFormat:  "%s",
+			PathFormat:  `"%s:${env:PATH}"`,
+			PathPattern: `^"(.*):\$\{env:PATH\}"$`,
+			ListFormat:  `"%s"`,
+		}
+	case "elvish":
+		// set-env PATH (get-env LVS_HOME):(get-env PATH)，$E:变量名称中的冒号会被解析为命名空间，因此使用get-env
+		return &ShellAdapter{
+			KvSeparator: " ",
+			VSeparator:  ":",
+			Wrappers: map[string]string{
+				"$E:": "",
+			},
+			Prefix:    "(get-env ",
+			Suffix:    ")",
+			SetPrefix: "set-env",
+		}
 	}
 	return &ShellAdapter{
-		ConfigPath:  getPath(shellType),
 		KvSeparator: "=",
 		VSeparator:  ":",
 		Prefix:      "$",
@@ -146,7 +219,8 @@ func NewShellAdapter(shellType, shellConfigPath string) *ShellAdapter {
 }
 
 type ShellAdapter struct {
-	ConfigPath     string            // 配置文件路径
+	shellType      string
+	configPath     string            // 配置文件路径，为空时根据shellType检测
 	KvSeparator    string            // 环境变量键值对分隔符
 	VSeparator     string            // 环境变量多值分隔符
 	Wrappers       map[string]string // 引用变量前缀、后缀
@@ -155,142 +229,55 @@ type ShellAdapter struct {
 	SetPrefix      string            // 设置环境变量前缀
 	SetFlag        string            // 设置环境变量标记
 	SetFlagPattern string            // 设置环境变量标记正则表达式
+	SetFormat      string            // 设置环境变量语句的格式，参数依次为名称与值，为空时由SetPrefix、SetFlag与KvSeparator拼接
+	SetPattern     string            // 匹配设置环境变量语句的正则表达式，分组依次为名称与值，为空时根据SetPrefix生成
+	Quote          string            // 环境变量值的格式，如"%s"，为空时仅在包含空格时添加双引号
+	ItemFormat     string            // PATH中每个目录的格式，为空时与环境变量值一致
+	RefFormat      string            // PATH中引用变量的目录的格式，如nushell的$"%s"，为空时与ItemFormat一致
+	PathFormat     string            // 在PATH前添加目录的值格式，参数为使用VSeparator连接的目录，需包含对PATH的引用，为空时在目录后追加PATH引用
+	PathPattern    string            // 从PATH的值中提取目录的正则表达式，与PathFormat对应
+	ListFormat     string            // 完整PATH目录列表的值格式，为空时使用VSeparator连接
 }
 
-func (a *ShellAdapter) SetEnvs(data []byte, envKeyValues map[string]string, pathValues []string) ([]byte, error) {
-	if envKeyValues == nil {
-		envKeyValues = map[string]string{}
+// ConfigPath 配置文件路径，nushell与pwsh需要启动Shell查询，因此仅在读写配置文件时获取
+func (a *ShellAdapter) ConfigPath() string {
+	if a.configPath == "" {
+		a.configPath = ShellConfigPath(a.shellType)
 	}
-	items := a.replacePathValues(pathValues)
-
-	var buf bytes.Buffer
-	if len(data) > 0 {
-		scanner := bufio.NewScanner(bytes.NewReader(data))
-		pattern := a.matchPattern()
-		re := regexp.MustCompile(pattern)
+	a.configPath, _ = homedir.Expand(a.configPath)
+	return a.configPath
+}
 
-		for scanner.Scan() {
-			line := scanner.Text()
-			lineTrim := strings.TrimSpace(line)
-			if lineTrim == "" || !strings.HasPrefix(lineTrim, a.SetPrefix) {
-				buf.WriteString(line + "\n")
-				continue
-			}
-			matches := re.FindStringSubmatch(lineTrim)
-			matchesCount := 3
-			if a.SetFlagPattern != "" {
-				matchesCount = 4
-			}
-			if len(matches) == matchesCount {
-				envKey := strings.TrimSpace(matches[matchesCount-2])
-				if envKeyValues[envKey] != "" {
-					continue
-				}
-				if "PATH" == envKey {
-					envValue := strings.TrimSpace(matches[matchesCount-1])
-					values := a.splitPathValue(envValue)
-					var copySlice []string
-					changed := false
-					for _, value := range values {
-						if items.contains(value) {
-							changed = true
-							continue
-						}
-						copySlice = append(copySlice, a.escapeEnvValue(value))
-					}
-					if changed {
-						if !a.isEmptyOrOnlyPath(copySlice) {
-							if a.SetFlagPattern != "" {
-								buf.WriteString(fmt.Sprintf("%s %s PATH %s\n", a.SetPrefix, matches[1], strings.Join(copySlice, a.VSeparator)))
-							} else {
-								buf.WriteString(a.exportEnv("PATH", strings.Join(copySlice, a.VSeparator)))
-							}
-						}
-						continue
-					}
-				}
-			}
-			buf.WriteString(line + "\n")
-		}
-		if err := scanner.Err(); err != nil {
-			return nil, err
-		}
+// ExportEnvs 生成仅在当前会话中生效的环境变量设置语句，不修改配置文件，paths为完整的PATH目录列表
+func (a *ShellAdapter) ExportEnvs(envKeyValues map[string]string, paths []string) string {
+	keys := make([]string, 0, len(envKeyValues))
+	for key := range envKeyValues {
+		keys = append(keys, key)
 	}
-	for key, value := range envKeyValues {
-		buf.WriteString(a.exportEnv(key, a.escapeEnvValue(value)))
+	sort.Strings(keys)
+	var buf strings.Builder
+	for _, key := range keys {
+		buf.WriteString(a.exportEnv(key, a.escapeEnvValue(envKeyValues[key])))
 	}
-	if len(items) > 0 {
-		buf.WriteString(a.exportEnv("PATH", items.toPath(a)))
-	}
-
-	return buf.Bytes(), nil
-}
-
-func (a *ShellAdapter) DelEnvs(data []byte, envKeys []string, pathValues []string) ([]byte, error) {
-	items := a.replacePathValues(pathValues)
-
-	var buf bytes.Buffer
-	keyMap := make(map[string]bool)
-	for _, key := range envKeys {
-		keyMap[key] = true
-	}
-	if len(data) > 0 {
-		scanner := bufio.NewScanner(bytes.NewReader(data))
-		pattern := a.matchPattern()
-		re := regexp.MustCompile(pattern)
-
-		for scanner.Scan() {
-			line := scanner.Text()
-			lineTrim := strings.TrimSpace(line)
-			if lineTrim == "" || !strings.HasPrefix(lineTrim, a.SetPrefix) {
-				buf.WriteString(line + "\n")
-				continue
-			}
-			matches := re.FindStringSubmatch(lineTrim)
-			matchesCount := 3
-			if a.SetFlagPattern != "" {
-				matchesCount = 4
-			}
-			if len(matches) == matchesCount {
-				envKey := strings.TrimSpace(matches[matchesCount-2])
-				if keyMap[envKey] {
-					continue
-				}
-				if "PATH" == envKey {
-					envValue := strings.TrimSpace(matches[matchesCount-1])
-					values := a.splitPathValue(envValue)
-					var copySlice []string
-					changed := false
-					for _, value := range values {
-						if items.contains(value) {
-							changed = true
-							continue
-						}
-						copySlice = append(copySlice, a.escapeEnvValue(value))
-					}
-					if changed {
-						if !a.isEmptyOrOnlyPath(copySlice) {
-							if a.SetFlagPattern != "" {
-								buf.WriteString(fmt.Sprintf("%s %s PATH %s\n", a.SetPrefix, matches[1], strings.Join(copySlice, a.VSeparator)))
-							} else {
-								buf.WriteString(a.exportEnv("PATH", strings.Join(copySlice, a.VSeparator)))
-							}
-						}
-						continue
-					}
-				}
-			}
-			buf.WriteString(line + "\n")
+	if len(paths) > 0 {
+		values := make([]string, len(paths))
+		for i, path := range paths {
+			values[i] = a.escapeItem(path)
 		}
-		if err := scanner.Err(); err != nil {
-			return nil, err
+		value := strings.Join(values, a.VSeparator)
+		if a.ListFormat != "" {
+			value = fmt.Sprintf(a.ListFormat, value)
 		}
+		buf.WriteString(a.exportEnv("PATH", value))
 	}
-	return buf.Bytes(), nil
+	return buf.String()
 }
 
 // 构建匹配导出环境变量的正则表达式
 func (a *ShellAdapter) matchPattern() string {
+	if a.SetPattern != "" {
+		return a.SetPattern
+	}
 	if a.SetFlagPattern == "" {
 		a.SetFlagPattern = a.SetFlag
 	}
@@ -300,7 +287,40 @@ func (a *ShellAdapter) matchPattern() string {
 	return fmt.Sprintf(`^%s\s+(%s)\s+([A-Za-z_][A-Za-z0-9_]*)%s(.*)$`, a.SetPrefix, a.SetFlagPattern, a.KvSeparator)
 }
 
+// matchEnv 解析设置环境变量的语句，返回标记、名称与值
+func (a *ShellAdapter) matchEnv(re *regexp.Regexp, line string) (string, string, string, bool) {
+	matches := re.FindStringSubmatch(line)
+	switch len(matches) {
+	case 3:
+		return "", strings.TrimSpace(matches[1]), strings.TrimSpace(matches[2]), true
+	case 4:
+		return matches[1], strings.TrimSpace(matches[2]), strings.TrimSpace(matches[3]), true
+	}
+	return "", "", "", false
+}
+
+// splitPath 拆分PATH的值，与PathPattern不匹配时返回false，此时不修改该语句
+func (a *ShellAdapter) splitPath(envValue string) ([]string, bool) {
+	if a.PathPattern != "" {
+		matches := regexp.MustCompile(a.PathPattern).FindStringSubmatch(envValue)
+		if len(matches) != 2 {
+			return nil, false
+		}
+		envValue = matches[1]
+	}
+	values := a.splitPathValue(envValue)
+	for _, format := range []string{a.ItemFormat, a.RefFormat} {
+		// 引号已在拆分时移除，如nushell的$"..."仅剩余$前缀
+		prefix, suffix, _ := strings.Cut(strings.ReplaceAll(format, `"`, ""), "%s")
+		for i, value := range values {
+			values[i] = strings.TrimSuffix(strings.TrimPrefix(value, prefix), suffix)
+		}
+	}
+	return values, true
+}
+
 type pathValue struct {
+	input     string // 原始值，如%NODE_HOME%/bin
 	result    string
 	extracted []string
 	wrappers  []string
@@ -321,18 +341,25 @@ func (i *pathValue) Contains(value string) bool {
 type pathValues []*pathValue
 
 func (i pathValues) contains(value string) bool {
+	return i.find(value) != nil
+}
+
+func (i pathValues) find(value string) *pathValue {
 	for _, w := range i {
 		if w.Contains(value) {
-			return true
+			return w
 		}
 	}
-	return false
+	return nil
 }
 
 func (i pathValues) toPath(a *ShellAdapter) string {
 	var list []string
 	for _, w := range i {
-		list = append(list, a.escapeEnvValue(w.result))
+		list = append(list, a.escapeItem(w.result))
+	}
+	if a.PathFormat != "" {
+		return fmt.Sprintf(a.PathFormat, strings.Join(list, a.VSeparator))
 	}
 	list = append(list, a.refEnvKey("PATH"))
 	return strings.Join(list, a.VSeparator)
@@ -363,6 +390,7 @@ func (a *ShellAdapter) replacePathValues(inputs []string) pathValues {
 			}
 		}
 		items = append(items, &pathValue{
+			input:     input,
 			result:    result,
 			extracted: extracted,
 			wrappers:  wrappers,
@@ -374,6 +402,10 @@ func (a *ShellAdapter) replacePathValues(inputs []string) pathValues {
 
 // 格式化导出环境变量语句
 func (a *ShellAdapter) exportEnv(key, value string) string {
+	if a.SetFormat != "" {
+		// $env:LVS_HOME = "/opt/lvs"
+		return fmt.Sprintf(a.SetFormat, key, value) + "\n"
+	}
 	if a.SetFlag != "" {
 		// set -x LVS_HOME /opt/lvs
 		return fmt.Sprintf("%s %s %s%s%s\n", a.SetPrefix, a.SetFlag, key, a.KvSeparator, value)
@@ -390,13 +422,27 @@ func (a *ShellAdapter) refEnvKey(envKey string) string {
 
 // escapeEnvValue 判断环境变量的值是否需要添加双引号或转义
 func (a *ShellAdapter) escapeEnvValue(value string) string {
-	// 主要是文件路径，暂时只处理空格
-	if strings.Contains(value, " ") {
+	if a.Quote != "" {
+		return fmt.Sprintf(a.Quote, value)
+	}
+	// 主要是文件路径，暂时只处理空格，引用变量前缀中的空格不需要处理，如elvish的(get-env
+	if strings.Contains(strings.ReplaceAll(value, a.Prefix, ""), " ") {
 		return fmt.Sprintf("\"%s\"", value)
 	}
 	return value
 }
 
+// escapeItem 格式化PATH中的目录
+func (a *ShellAdapter) escapeItem(value string) string {
+	if a.RefFormat != "" && strings.Contains(value, a.Prefix) {
+		return fmt.Sprintf(a.RefFormat, value)
+	}
+	if a.ItemFormat != "" {
+		return fmt.Sprintf(a.ItemFormat, value)
+	}
+	return a.escapeEnvValue(value)
+}
+
 // 将指定的环境变量值进行拆分
 func (a *ShellAdapter) splitPathValue(envValue string) []string {
 	var result []string
@@ -404,6 +450,7 @@ func (a *ShellAdapter) splitPathValue(envValue string) []string {
 	inQuotes := false
 	quoteChar := rune(0) // 记录使用的引号类型（单引号或双引号）
 	escaped := false
+	depth := 0 // 括号的嵌套层级，括号中的分隔符不拆分，如pwsh的${env:PATH}
 
 	for _, char := range envValue {
 		switch {
@@ -424,13 +471,23 @@ func (a *ShellAdapter) splitPathValue(envValue string) []string {
 				inQuotes = true
 				quoteChar = char
 			}
-		case char == rune(a.VSeparator[0]) && !inQuotes:
+		case char == rune(a.VSeparator[0]) && !inQuotes && depth == 0:
 			// 遇到分隔符且不在引号内，拆分
 			if current.Len() > 0 {
 				result = append(result, current.String())
 				current.Reset()
 			}
 		default:
+			if !inQuotes {
+				switch char {
+				case '{', '(':
+					depth++
+				case '}', ')':
+					if depth > 0 {
+						depth--
+					}
+				}
+			}
 			// 追加正常字符
 			current.WriteRune(char)
 			escaped = false
@@ -448,6 +505,9 @@ func (a *ShellAdapter) splitPathValue(envValue string) []string {
 // 判断环境变量值是否为空或仅包含PATH引用
 func (a *ShellAdapter) isEmptyOrOnlyPath(envValues []string) bool {
 	length := len(envValues)
+	if length == 0 {
+		return true
+	}
 	if length == 1 {
 		value := envValues[0]
 		if value == a.refEnvKey("PATH") {
@@ -0,0 +1,112 @@
+//go:build !windows
+
+package shell
+
+import (
+	"testing"
+)
+
+func block(lines string) string {
+	return BlockBegin + "\n" + blockNotice + "\n" + lines + BlockEnd + "\n"
+}
+
+func TestShellAdapter(t *testing.T) {
+	envKeyValues := map[string]string{"NODE_HOME": "/opt/lvs/symlink/nodejs"}
+	pathValues := []string{"%NODE_HOME%/bin"}
+	tests := map[string]string{
+		"bash":   "export NODE_HOME=/opt/lvs/symlink/nodejs\nexport PATH=$NODE_HOME/bin:$PATH\n",
+		"fish":   "set -x NODE_HOME /opt/lvs/symlink/nodejs\nset -x PATH $NODE_HOME/bin $PATH\n",
+		"csh":    "setenv NODE_HOME /opt/lvs/symlink/nodejs\nsetenv PATH ${NODE_HOME}/bin:${PATH}\n",
+		"nu":     "$env.NODE_HOME = \"/opt/lvs/symlink/nodejs\"\n$env.PATH = ($env.PATH | split row (char esep) | prepend [$\"($env.NODE_HOME)/bin\"])\n",
+		"pwsh":   "$env:NODE_HOME = \"/opt/lvs/symlink/nodejs\"\n$env:PATH = \"${env:NODE_HOME}/bin:${env:PATH}\"\n",
+		"elvish": "set-env NODE_HOME /opt/lvs/symlink/nodejs\nset-env PATH (get-env NODE_HOME)/bin:(get-env PATH)\n",
+	}
+	for shellType, lines := range tests {
+		adapter := NewShellAdapter(shellType, "/dev/null")
+		expected := "# user config\n" + block(lines) + "# after\n"
+		data, err := adapter.SetEnvs([]byte("# user config"), envKeyValues, pathValues)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, "# after\n"...)
+		if string(data) != expected {
+			t.Errorf("[%s] expected:\n%s\ngot:\n%s", shellType, expected, data)
+		}
+		// 重复设置时重新生成配置块
+		if data, err = adapter.SetEnvs(data, envKeyValues, pathValues); err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Errorf("[%s] expected after reset:\n%s\ngot:\n%s", shellType, expected, data)
+		}
+		if data, err = adapter.DelEnvs(data, []string{"NODE_HOME"}, pathValues); err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "# user config\n# after\n" {
+			t.Errorf("[%s] expected the block to be removed, got:\n%s", shellType, data)
+		}
+	}
+}
+
+func TestShellAdapterPathOrder(t *testing.T) {
+	adapter := NewShellAdapter("bash", "/dev/null")
+	data, _ := adapter.SetEnvs(nil, map[string]string{"GOROOT": "/opt/go"}, []string{"%GOROOT%/bin"})
+	data, _ = adapter.SetEnvs(data, map[string]string{"LVS_HOME": "/opt/lvs bin"}, []string{"%LVS_HOME%"})
+	data, _ = adapter.AddLines(data, []string{`eval "$(lvs completion bash)"`})
+	expected := block("export GOROOT=/opt/go\nexport LVS_HOME=\"/opt/lvs bin\"\nexport PATH=$LVS_HOME:$GOROOT/bin:$PATH\neval \"$(lvs completion bash)\"\n")
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestShellAdapterMigrate(t *testing.T) {
+	adapter := NewShellAdapter("bash", "/dev/null")
+	data := "# user config\r\n" +
+		"export PATH=/usr/local/go/bin:$GOROOT/bin:$PATH\n" +
+		"export GOROOT=/opt/go\n" +
+		"export PATH=$GOROOT/bin:$PATH\n" +
+		"alias ll='ls -l'\n" +
+		"export NODE_HOME=/opt/node/\n" +
+		"export GOPATH=/home/user/work\n" +
+		"export PATH=$NODE_HOME/bin:$PATH"
+	envValues := map[string][]string{"GOROOT": {"/opt/go"}, "GOPATH": {"/home/user/go"}, "NODE_HOME": {"/opt/node"}}
+	result, err := adapter.Migrate([]byte(data), envValues, []string{"%GOROOT%/bin", "%NODE_HOME%/bin"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# user config\r\n" +
+		"export PATH=/usr/local/go/bin:$GOROOT/bin:$PATH\n" +
+		block("export GOROOT=/opt/go\nexport NODE_HOME=/opt/node/\nexport PATH=$NODE_HOME/bin:$GOROOT/bin:$PATH\n") +
+		"alias ll='ls -l'\n" +
+		"export GOPATH=/home/user/work\n"
+	if string(result) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result)
+	}
+	// 配置块已存在且没有需要移动的语句时保持不变
+	if again, _ := adapter.Migrate(result, envValues, []string{"%GOROOT%/bin", "%NODE_HOME%/bin"}, nil); string(again) != expected {
+		t.Errorf("expected no changes, got:\n%s", again)
+	}
+}
+
+func TestShellAdapterKeepsOtherPaths(t *testing.T) {
+	tests := map[string]string{
+		"pwsh": "$env:PATH = \"/usr/local/bin:$env:PATH\"\n",
+		"nu":   "$env.PATH = ($env.PATH | split row (char esep) | prepend [\"/usr/local/bin\" $\"($env.GOROOT)/bin\"])\n",
+	}
+	for shellType, data := range tests {
+		adapter := NewShellAdapter(shellType, "/dev/null")
+		result, err := adapter.Migrate([]byte(data), map[string][]string{"GOROOT": nil}, []string{"%GOROOT%/bin"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(result) != data {
+			t.Errorf("[%s] expected:\n%s\ngot:\n%s", shellType, data, result)
+		}
+		if result, err = adapter.DelEnvs([]byte(data), []string{"GOROOT"}, []string{"%GOROOT%/bin"}); err != nil {
+			t.Fatal(err)
+		}
+		if string(result) != data {
+			t.Errorf("[%s] expected:\n%s\ngot:\n%s", shellType, data, result)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// ParseChecksums 解析SHASUMS256.txt格式的内容，返回文件名与摘要的映射
+//
+//	a1b2c3...  node-v20.11.1-linux-x64.tar.gz
+func ParseChecksums(data []byte) map[string]string {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || len(fields[0]) != sha256.Size*2 {
+			continue
+		}
+		if _, err := hex.DecodeString(fields[0]); err != nil {
+			continue
+		}
+		// 二进制模式下文件名以*开头
+		name := strings.TrimPrefix(fields[1], "*")
+		checksums[name] = strings.ToLower(fields[0])
+	}
+	return checksums
+}
+
+// NewChecksum 创建计算摘要的Hash
+func NewChecksum() hash.Hash {
+	return sha256.New()
+}
+
+// VerifyChecksum 校验计算所得摘要与期望摘要是否一致
+func VerifyChecksum(name, expected string, h hash.Hash) error {
+	actual := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(strings.TrimSpace(expected), actual) {
+		return fmt.Errorf("checksum mismatch for [%s], expected sha256 %s but got %s", name, expected, actual)
+	}
+	return nil
+}
+
+// FileChecksum 计算文件的摘要
+func FileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := NewChecksum()
+	if _, err = io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
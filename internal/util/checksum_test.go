@@ -0,0 +1,30 @@
+package util
+
+import "testing"
+
+func TestParseChecksums(t *testing.T) {
+	data := []byte(`ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb  node-v20.11.1-linux-x64.tar.gz
+3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d *node-v20.11.1-win-x64.zip
+
+invalid line
+`)
+	checksums := ParseChecksums(data)
+	if len(checksums) != 2 {
+		t.Fatalf("expected 2 checksums, got %d", len(checksums))
+	}
+	if checksums["node-v20.11.1-win-x64.zip"] != "3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d" {
+		t.Fatal("binary mode file name is not parsed")
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	h := NewChecksum()
+	_, _ = h.Write([]byte("lvs"))
+	if err := VerifyChecksum("lvs", "0000", h); err == nil {
+		t.Fatal("expected checksum mismatch")
+	}
+	// echo -n lvs | sha256sum
+	if err := VerifyChecksum("lvs", "F3497D75B9F10B708886174A926D7986B1D550BE7C8054BC42949A39FAC2A83D", h); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,194 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PartialMark 未完成下载文件名中的标识，保留原扩展名以便按扩展名解压，如 go1.22.0.linux-amd64.part.tar.gz
+const PartialMark = ".part."
+
+// Partial 可断点续传的下载文件，文件名固定，中断后下次下载时从已下载的位置继续
+type Partial struct {
+	Path      string `json:"-"`
+	Url       string `json:"url"`
+	Validator string `json:"validator"` // 响应中的ETag或Last-Modified，用于If-Range
+	Size      int64  `json:"size"`      // 文件总大小，未知时为-1
+	Offset    int64  `json:"-"`         // 已下载的字节数
+}
+
+// OpenPartial 打开dir目录中baseName.ext对应的未完成下载文件，不存在或元数据无效时从头开始下载
+func OpenPartial(dir, baseName, ext, url string) (*Partial, error) {
+	p := &Partial{Path: filepath.Join(dir, baseName+PartialMark+ext), Url: url, Size: -1}
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return p, nil
+		}
+		return nil, err
+	}
+	data, err := os.ReadFile(p.metaPath())
+	if err != nil || json.Unmarshal(data, p) != nil || (p.Size >= 0 && info.Size() >= p.Size) {
+		// 元数据缺失或文件已达到总大小但未通过校验，均无法安全续传
+		return p.reset(url)
+	}
+	p.Retarget(url)
+	p.Offset = info.Size()
+	return p, nil
+}
+
+func (p *Partial) metaPath() string {
+	return p.Path[:strings.LastIndex(p.Path, PartialMark)] + PartialMark + "json"
+}
+
+func (p *Partial) reset(url string) (*Partial, error) {
+	if err := p.Remove(); err != nil {
+		return nil, err
+	}
+	return &Partial{Path: p.Path, Url: url, Size: -1}, nil
+}
+
+// Retarget 切换下载地址，镜像地址发生变化时校验标识不再适用，仍可尝试续传，最终由摘要保证文件完整
+func (p *Partial) Retarget(url string) {
+	if p.Url != url {
+		p.Url = url
+		p.Validator = ""
+	}
+}
+
+// Request 创建下载请求，存在已下载内容时设置Range与If-Range请求头
+func (p *Partial) Request() (*http.Request, error) {
+	req, err := http.NewRequest("GET", p.Url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.Offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", p.Offset))
+		if p.Validator != "" {
+			req.Header.Set("If-Range", p.Validator)
+		}
+	}
+	return req, nil
+}
+
+var contentRangeRegexp = regexp.MustCompile(`^bytes (\d+)-\d+/(\d+|\*)$`)
+
+// Open 根据响应打开写入文件，服务器支持续传时追加写入并将已下载的内容写入h，否则从头写入
+func (p *Partial) Open(resp *http.Response, h hash.Hash) (*os.File, error) {
+	flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		match := contentRangeRegexp.FindStringSubmatch(resp.Header.Get("Content-Range"))
+		if match == nil {
+			return nil, fmt.Errorf("invalid Content-Range: %s", resp.Header.Get("Content-Range"))
+		}
+		if start, _ := strconv.ParseInt(match[1], 10, 64); start != p.Offset {
+			return nil, fmt.Errorf("unexpected Content-Range: %s, expected start %d", resp.Header.Get("Content-Range"), p.Offset)
+		}
+		p.Size = -1
+		if match[2] != "*" {
+			p.Size, _ = strconv.ParseInt(match[2], 10, 64)
+		}
+		flag = os.O_CREATE | os.O_RDWR | os.O_APPEND
+	case http.StatusOK:
+		p.Offset = 0
+		p.Size = resp.ContentLength
+	default:
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	p.Validator = resp.Header.Get("ETag")
+	if p.Validator == "" || strings.HasPrefix(p.Validator, "W/") {
+		// 弱ETag不能用于If-Range
+		p.Validator = resp.Header.Get("Last-Modified")
+	}
+	if err := os.MkdirAll(filepath.Dir(p.Path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(p.metaPath(), data, 0644); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(p.Path, flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if p.Offset > 0 {
+		if _, err = io.Copy(h, io.NewSectionReader(file, 0, p.Offset)); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+// Verify 校验文件大小与摘要，校验失败时删除文件，避免下次继续使用错误的内容
+func (p *Partial) Verify(name, expected string, h hash.Hash) error {
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return err
+	}
+	if p.Size >= 0 && info.Size() != p.Size {
+		// 大小不足时说明下载中断，保留文件以便续传
+		if info.Size() < p.Size {
+			return fmt.Errorf("incomplete download of [%s], expected %d bytes but got %d", name, p.Size, info.Size())
+		}
+		_ = p.Remove()
+		return fmt.Errorf("size mismatch for [%s], expected %d bytes but got %d", name, p.Size, info.Size())
+	}
+	if err = VerifyChecksum(name, expected, h); err != nil {
+		_ = p.Remove()
+		return err
+	}
+	return nil
+}
+
+// Remove 删除未完成下载文件及其元数据
+func (p *Partial) Remove() error {
+	for _, path := range []string{p.Path, p.metaPath()} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// CleanPartials 清理dir目录中超过expire未更新的未完成下载文件
+func CleanPartials(dir string, expire time.Duration) error {
+	if expire <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.Contains(entry.Name(), PartialMark) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if time.Since(info.ModTime()) > expire {
+			if err = os.Remove(filepath.Join(dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package util
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func downloadPartial(t *testing.T, dir, url string, limit int64) (*Partial, error) {
+	t.Helper()
+	p, err := OpenPartial(dir, "archive", "tar.gz", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := p.Request()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	h := NewChecksum()
+	file, err := p.Open(resp, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body io.Reader = resp.Body
+	if limit > 0 {
+		// 模拟下载中断
+		body = io.LimitReader(resp.Body, limit)
+	}
+	_, err = io.Copy(io.MultiWriter(file, h), body)
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(partialContent)
+	return p, p.Verify("archive.tar.gz", hex.EncodeToString(sum[:]), h)
+}
+
+var partialContent = bytes.Repeat([]byte("0123456789abcdef"), 4096)
+
+func TestPartialResume(t *testing.T) {
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "archive.tar.gz", time.Time{}, bytes.NewReader(partialContent))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	p, err := downloadPartial(t, dir, server.URL, 1000)
+	if err == nil || !strings.Contains(err.Error(), "incomplete") {
+		t.Fatalf("expected incomplete download, got %v", err)
+	}
+	if info, err := os.Stat(p.Path); err != nil || info.Size() != 1000 {
+		t.Fatalf("partial file should be kept, %v", err)
+	}
+
+	p, err = downloadPartial(t, dir, server.URL, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ranges[1] != "bytes=1000-" {
+		t.Fatalf("expected range request, got %q", ranges[1])
+	}
+	if data, _ := os.ReadFile(p.Path); !bytes.Equal(data, partialContent) {
+		t.Fatal("resumed file content mismatch")
+	}
+}
+
+func TestPartialRestart(t *testing.T) {
+	etag := `"v1"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "archive.tar.gz", time.Time{}, bytes.NewReader(partialContent))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	if _, err := downloadPartial(t, dir, server.URL, 1000); err == nil {
+		t.Fatal("expected incomplete download")
+	}
+	// 远程文件变化后If-Range不匹配，服务器返回完整内容
+	etag = `"v2"`
+	p, err := downloadPartial(t, dir, server.URL, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(p.Path); !bytes.Equal(data, partialContent) {
+		t.Fatal("restarted file content mismatch")
+	}
+}
+
+func TestPartialChecksumMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "archive.tar.gz", time.Time{}, bytes.NewReader(append([]byte("x"), partialContent[1:]...)))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	p, err := downloadPartial(t, dir, server.URL, 0)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if Exists(p.Path) {
+		t.Fatal("corrupted file should be removed")
+	}
+}
+
+func TestCleanPartials(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "stale"+PartialMark+"tar.gz")
+	fresh := filepath.Join(dir, "fresh"+PartialMark+"tar.gz")
+	other := filepath.Join(dir, "other.tar.gz")
+	for _, path := range []string{stale, fresh, other} {
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	for _, path := range []string{stale, other} {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := CleanPartials(dir, 24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if Exists(stale) || !Exists(fresh) || !Exists(other) {
+		t.Fatal("only stale partial files should be removed")
+	}
+}
@@ -314,3 +314,17 @@ func ExecExists(execPath string) bool {
 	}
 	return false
 }
+
+// FormatBytes 将字节数格式化为便于阅读的形式，如 68.5 MB
+func FormatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
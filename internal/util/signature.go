@@ -0,0 +1,61 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"strings"
+)
+
+// ReadKeyRing 读取OpenPGP公钥环，支持ASCII Armor格式与二进制格式（gpg --export）
+func ReadKeyRing(data []byte) (openpgp.EntityList, error) {
+	var keyring openpgp.EntityList
+	if bytes.Contains(data, []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----")) {
+		// 允许多个公钥块直接拼接
+		for _, block := range strings.SplitAfter(string(data), "-----END PGP PUBLIC KEY BLOCK-----") {
+			if !strings.Contains(block, "-----BEGIN PGP PUBLIC KEY BLOCK-----") {
+				continue
+			}
+			entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(block))
+			if err != nil {
+				return nil, err
+			}
+			keyring = append(keyring, entities...)
+		}
+	} else if len(data) > 0 && data[0]&0x80 != 0 {
+		// 二进制格式的首个字节最高位始终为1
+		entities, err := openpgp.ReadKeyRing(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		keyring = entities
+	}
+	if len(keyring) == 0 {
+		return nil, errors.New("no public key found in keyring")
+	}
+	return keyring, nil
+}
+
+// VerifySignature 使用公钥环校验分离签名，返回签名者的主密钥指纹
+func VerifySignature(keyring openpgp.EntityList, signed, signature []byte) (string, error) {
+	var signer *openpgp.Entity
+	var err error
+	if bytes.Contains(signature, []byte("-----BEGIN PGP SIGNATURE-----")) {
+		signer, err = openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(signed), bytes.NewReader(signature), nil)
+	} else {
+		signer, err = openpgp.CheckDetachedSignature(keyring, bytes.NewReader(signed), bytes.NewReader(signature), nil)
+	}
+	// 历史版本可能由已过期的密钥签名，签名本身有效即可
+	if err != nil && !errors.Is(err, pgperrors.ErrKeyExpired) {
+		if errors.Is(err, pgperrors.ErrUnknownIssuer) {
+			return "", errors.New("signature was made by a key that is not in the keyring")
+		}
+		return "", err
+	}
+	if signer == nil {
+		return "", errors.New("unable to determine the signer")
+	}
+	return strings.ToUpper(fmt.Sprintf("%x", signer.PrimaryKey.Fingerprint)), nil
+}
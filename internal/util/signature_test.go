@@ -0,0 +1,66 @@
+package util
+
+import (
+	"bytes"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"testing"
+)
+
+func TestVerifySignature(t *testing.T) {
+	signer, err := openpgp.NewEntity("lvs", "test", "lvs@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := openpgp.NewEntity("other", "test", "other@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keyringData bytes.Buffer
+	writer, err := armor.Encode(&keyringData, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = signer.Serialize(writer); err != nil {
+		t.Fatal(err)
+	}
+	_ = writer.Close()
+
+	keyring, err := ReadKeyRing(keyringData.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb  node-v20.11.1-linux-x64.tar.gz\n")
+
+	var armored bytes.Buffer
+	if err = openpgp.ArmoredDetachSign(&armored, signer, bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = VerifySignature(keyring, data, armored.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	var binary bytes.Buffer
+	if err = openpgp.DetachSign(&binary, signer, bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = VerifySignature(keyring, data, binary.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = VerifySignature(keyring, append(data, '\n'), armored.Bytes()); err == nil {
+		t.Fatal("expected tampered content to fail verification")
+	}
+
+	var unknown bytes.Buffer
+	if err = openpgp.ArmoredDetachSign(&unknown, other, bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = VerifySignature(keyring, data, unknown.Bytes()); err == nil {
+		t.Fatal("expected signature of unknown key to fail verification")
+	}
+}
+
+func TestReadKeyRing(t *testing.T) {
+	if _, err := ReadKeyRing([]byte("# node.js release keys\n")); err == nil {
+		t.Fatal("expected empty keyring to fail")
+	}
+}